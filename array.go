@@ -1,18 +1,26 @@
 package timestreamdriver
 
 import (
+	"bytes"
 	"database/sql"
-	"encoding/json"
+	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
 )
 
 type customType = interface {
 	sql.Scanner
-	// TODO: driver.Valuer
+	driver.Valuer
 }
 
 // Array converts `x` into corresponding concrete scannable types.
+//
+// Slices other than listed below, such as `*[]*int64`, `*[][]int64` and pointers to named slice types, are scanned in the same way as ArrayOf.
+// If `x` is not a slice nor a pointer to slice, the returned value reports the error on Scan and Value.
 func Array(x interface{}) customType {
 	switch x := x.(type) {
 	case []string:
@@ -31,9 +39,158 @@ func Array(x interface{}) customType {
 		return (*BooleanArray)(x)
 	case []bool:
 		return (*BooleanArray)(&x)
+	case *[]int64:
+		return (*ArrayOf[int64])(x)
+	case []int64:
+		return (*ArrayOf[int64])(&x)
+	case *[]uint64:
+		return (*ArrayOf[uint64])(x)
+	case []uint64:
+		return (*ArrayOf[uint64])(&x)
+	case *[]time.Time:
+		return (*ArrayOf[time.Time])(x)
+	case []time.Time:
+		return (*ArrayOf[time.Time])(&x)
+	}
+	return reflectArray(x)
+}
+
+// reflectArray returns the scanner of the slice that is not supported by Array with concrete types.
+func reflectArray(x interface{}) customType {
+	rv := reflect.ValueOf(x)
+	switch {
+	case rv.Kind() == reflect.Slice:
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		return &sliceArray{ptr: ptr}
+	case rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Slice:
+		return &sliceArray{ptr: rv}
+	default:
+		return &invalidArray{err: fmt.Errorf("timestream: cannot use %T as array", x)}
+	}
+}
+
+// sliceArray is a pointer to slice that scannable by database/sql in the same way as ArrayOf.
+type sliceArray struct {
+	ptr reflect.Value
+}
+
+var _ interface {
+	customType
+	bareValue
+} = &sliceArray{}
+
+func (a *sliceArray) Scan(src interface{}) error {
+	return scanArray(a.ptr.Elem(), src)
+}
+
+func (a *sliceArray) Value() (driver.Value, error) {
+	buf := new(bytes.Buffer)
+	if err := writeArrayLiteral(buf, a.ptr.Elem()); err != nil {
+		return nil, err
 	}
+	return buf.String(), nil
+}
+
+func (*sliceArray) IsBareValue() {}
+
+// invalidArray is returned by Array for values that are not slices.
+type invalidArray struct {
+	err error
+}
 
-	return nil // TODO
+func (a *invalidArray) Scan(interface{}) error {
+	return a.err
+}
+
+func (a *invalidArray) Value() (driver.Value, error) {
+	return nil, a.err
+}
+
+// ArrayOf is a wrapper type of []T that scannable by database/sql and usable as query parameters.
+//
+// Elements are converted in the same way as Row fields: T may be any type that scalar values are converted into
+// (e.g. int64, uint64, float64, string, bool, time.Time), a pointer to them for arrays that have NULLs,
+// or slices and ArrayOf for nested arrays.
+//
+// TIME elements are converted into time.Time on January 1, year 1 UTC.
+// A NULL ARRAY column is scanned as nil ArrayOf.
+//
+// As a query parameter it is rendered as an ARRAY literal such as `ARRAY[1,2,3]`.
+type ArrayOf[T any] []T
+
+var _ interface {
+	customType
+	bareValue
+} = &ArrayOf[int64]{}
+
+func (a *ArrayOf[T]) Scan(src interface{}) error {
+	return scanArray(reflect.ValueOf(a).Elem(), src)
+}
+
+// scanArray stores the array column into the slice dst converting elements in the same way as Row fields.
+func scanArray(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	cd, err := arrayDatum(src)
+	if err != nil {
+		return err
+	}
+	xs := reflect.MakeSlice(dst.Type(), len(cd.datum.ArrayValue), len(cd.datum.ArrayValue))
+	for i, elem := range cd.datum.ArrayValue {
+		v, err := datumValue(elem, cd.columnInfo.Type.ArrayColumnInfo, cd.opts)
+		if err != nil {
+			return err
+		}
+		if err := assignValue(xs.Index(i), v); err != nil {
+			return fmt.Errorf("element #%d: %w", i, err)
+		}
+	}
+	dst.Set(xs)
+	return nil
+}
+
+// Value renders the array as an ARRAY literal.
+func (a ArrayOf[T]) Value() (driver.Value, error) {
+	buf := new(bytes.Buffer)
+	if err := writeArrayLiteral(buf, reflect.ValueOf([]T(a))); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+func (ArrayOf[T]) IsBareValue() {}
+
+// writeArrayLiteral writes the slice as ARRAY literal; nil elements are written as NULL.
+func writeArrayLiteral(buf *bytes.Buffer, rv reflect.Value) error {
+	buf.WriteString("ARRAY[")
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		if err := writeArrayElement(buf, rv.Index(i)); err != nil {
+			return fmt.Errorf("element #%d: %w", i, err)
+		}
+	}
+	buf.WriteString("]")
+	return nil
+}
+
+func writeArrayElement(buf *bytes.Buffer, elem reflect.Value) error {
+	if (elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface) && elem.IsNil() {
+		buf.WriteString("NULL")
+		return nil
+	}
+	converted, err := convertParam(elem.Interface())
+	if err != nil {
+		return err
+	}
+	if rv := reflect.ValueOf(converted); isSliceParam(rv) {
+		return writeArrayLiteral(buf, rv)
+	}
+	return formatParam(buf, converted)
 }
 
 // StringArray is a wrapper type of []string that scannable by database/sql
@@ -42,96 +199,143 @@ type StringArray []string
 var _ customType = &StringArray{}
 
 func (a *StringArray) Scan(src interface{}) error {
-	switch src := src.(type) {
-	case []byte:
-		var cd columnDatum
-		if err := json.Unmarshal(src, &cd); err != nil {
+	elems, err := arrayElements(src)
+	if err != nil {
+		return err
+	}
+	xs := make([]string, len(elems))
+	for i, v := range elems {
+		s, err := scalarElement(i, v)
+		if err != nil {
 			return err
 		}
-		xs := make([]string, len(cd.Datum.ArrayValue))
-		for i, v := range cd.Datum.ArrayValue {
-			xs[i] = *v.ScalarValue
-		}
-		*a = StringArray(xs)
-		return nil
-	default:
-		return fmt.Errorf("timestream: cannot convert %T", src)
+		xs[i] = s
 	}
+	*a = StringArray(xs)
+	return nil
 }
 
+func (a StringArray) Value() (driver.Value, error) {
+	return ArrayOf[string](a).Value()
+}
+
+func (StringArray) IsBareValue() {}
+
 // IntegerArray is a wrapper type of []int that scannable by database/sql
 type IntegerArray []int
 
 var _ customType = &IntegerArray{}
 
 func (a *IntegerArray) Scan(src interface{}) error {
-	switch src := src.(type) {
-	case []byte:
-		var cd columnDatum
-		if err := json.Unmarshal(src, &cd); err != nil {
+	elems, err := arrayElements(src)
+	if err != nil {
+		return err
+	}
+	xs := make([]int, len(elems))
+	for i, v := range elems {
+		s, err := scalarElement(i, v)
+		if err != nil {
 			return err
 		}
-		xs := make([]int, len(cd.Datum.ArrayValue))
-		for i, v := range cd.Datum.ArrayValue {
-			parsed, err := strconv.ParseInt(*v.ScalarValue, 10, 64)
-			if err != nil {
-				return err
-			}
-			xs[i] = int(parsed)
+		parsed, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
 		}
-		*a = IntegerArray(xs)
-		return nil
-	default:
-		return fmt.Errorf("timestream: cannot convert %T", src)
+		xs[i] = int(parsed)
 	}
+	*a = IntegerArray(xs)
+	return nil
+}
+
+func (a IntegerArray) Value() (driver.Value, error) {
+	return ArrayOf[int](a).Value()
 }
 
+func (IntegerArray) IsBareValue() {}
+
 // FloatArray is a wrapper type of []float64 that scannable by database/sql
 type FloatArray []float64
 
 var _ customType = &FloatArray{}
 
 func (a *FloatArray) Scan(src interface{}) error {
-	switch src := src.(type) {
-	case []byte:
-		var cd columnDatum
-		if err := json.Unmarshal(src, &cd); err != nil {
+	elems, err := arrayElements(src)
+	if err != nil {
+		return err
+	}
+	xs := make([]float64, len(elems))
+	for i, v := range elems {
+		s, err := scalarElement(i, v)
+		if err != nil {
 			return err
 		}
-		xs := make([]float64, len(cd.Datum.ArrayValue))
-		for i, v := range cd.Datum.ArrayValue {
-			parsed, err := strconv.ParseFloat(*v.ScalarValue, 64)
-			if err != nil {
-				return err
-			}
-			xs[i] = parsed
+		parsed, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
 		}
-		*a = FloatArray(xs)
-		return nil
-	default:
-		return fmt.Errorf("timestream: cannot convert %T", src)
+		xs[i] = parsed
 	}
+	*a = FloatArray(xs)
+	return nil
+}
+
+func (a FloatArray) Value() (driver.Value, error) {
+	return ArrayOf[float64](a).Value()
 }
 
+func (FloatArray) IsBareValue() {}
+
 // BooleanArray is a wrapper type of []bool that scannable by database/sql
 type BooleanArray []bool
 
 var _ customType = &BooleanArray{}
 
 func (a *BooleanArray) Scan(src interface{}) error {
-	switch src := src.(type) {
-	case []byte:
-		var cd columnDatum
-		if err := json.Unmarshal(src, &cd); err != nil {
+	elems, err := arrayElements(src)
+	if err != nil {
+		return err
+	}
+	xs := make([]bool, len(elems))
+	for i, v := range elems {
+		s, err := scalarElement(i, v)
+		if err != nil {
 			return err
 		}
-		xs := make([]bool, len(cd.Datum.ArrayValue))
-		for i, v := range cd.Datum.ArrayValue {
-			xs[i] = *v.ScalarValue == "true"
-		}
-		*a = BooleanArray(xs)
-		return nil
-	default:
-		return fmt.Errorf("timestream: cannot convert %T", src)
+		xs[i] = s == "true"
+	}
+	*a = BooleanArray(xs)
+	return nil
+}
+
+func (a BooleanArray) Value() (driver.Value, error) {
+	return ArrayOf[bool](a).Value()
+}
+
+func (BooleanArray) IsBareValue() {}
+
+// arrayElements returns elements of the array column value.
+func arrayElements(src interface{}) ([]*timestreamquery.Datum, error) {
+	cd, err := arrayDatum(src)
+	if err != nil {
+		return nil, err
+	}
+	return cd.datum.ArrayValue, nil
+}
+
+func arrayDatum(src interface{}) (*columnDatum, error) {
+	cd, err := columnDatumOf(src)
+	if err != nil {
+		return nil, err
+	}
+	if cd.columnInfo.Type.ArrayColumnInfo == nil {
+		return nil, fmt.Errorf("timestream: cannot convert non-array column into array")
+	}
+	return cd, nil
+}
+
+func scalarElement(i int, elem *timestreamquery.Datum) (string, error) {
+	if elem.ScalarValue == nil {
+		return "", fmt.Errorf("timestream: cannot convert element #%d: not a scalar value", i)
 	}
+	return *elem.ScalarValue, nil
 }
@@ -1,8 +1,15 @@
 package timestreamdriver
 
 import (
+	"context"
+	"database/sql/driver"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
 )
 
 func TestArray(t *testing.T) {
@@ -15,7 +22,7 @@ func TestArray(t *testing.T) {
 		{"integers", []int{1, 2}, &IntegerArray{1, 2}},
 		{"float values", []float64{1.0, 2.0}, &FloatArray{1.0, 2.0}},
 		{"booleans", []bool{false, true}, &BooleanArray{false, true}},
-		{"unhandleable type", "", nil},
+		{"int64 values", []int64{1, 2}, &ArrayOf[int64]{1, 2}},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -25,3 +32,261 @@ func TestArray(t *testing.T) {
 		})
 	}
 }
+
+type hostIDs []int64
+
+func TestArray_Reflect(t *testing.T) {
+	null := &timestreamquery.Datum{NullValue: aws.Bool(true)}
+	nested := &timestreamquery.ColumnInfo{Name: aws.String("xs"), Type: &timestreamquery.Type{ArrayColumnInfo: arrayColumn("", timestreamquery.ScalarTypeBigint)}}
+	t.Run("nullable", func(t *testing.T) {
+		got := []*int64{}
+		src := &columnDatum{&timestreamquery.Datum{ArrayValue: []*timestreamquery.Datum{scalarDatum("1"), null}}, arrayColumn("xs", timestreamquery.ScalarTypeBigint), scanOptions{}}
+		if err := Array(&got).Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0] == nil || *got[0] != 1 || got[1] != nil {
+			t.Errorf("unexpected: %#v", got)
+		}
+	})
+	t.Run("nested", func(t *testing.T) {
+		var got [][]int64
+		src := &columnDatum{&timestreamquery.Datum{ArrayValue: []*timestreamquery.Datum{arrayValue("1", "2"), arrayValue()}}, nested, scanOptions{}}
+		if err := Array(&got).Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if expected := [][]int64{{1, 2}, {}}; !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, got)
+		}
+	})
+	t.Run("named type", func(t *testing.T) {
+		var got hostIDs
+		if err := Array(&got).Scan(&columnDatum{arrayValue("1", "2"), arrayColumn("xs", timestreamquery.ScalarTypeBigint), scanOptions{}}); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (hostIDs{1, 2}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, got)
+		}
+		if err := Array(&got).Scan(nil); err != nil || got != nil {
+			t.Errorf("NULL: expected nil but got %#v err=%v", got, err)
+		}
+	})
+	t.Run("value", func(t *testing.T) {
+		one := int64(1)
+		v, err := Array(hostIDs{1, 2}).Value()
+		if err != nil || v != "ARRAY[1,2]" {
+			t.Errorf("expected ARRAY[1,2] but got %#v err=%v", v, err)
+		}
+		v, err = Array(&[]*int64{&one, nil}).Value()
+		if err != nil || v != "ARRAY[1,NULL]" {
+			t.Errorf("expected ARRAY[1,NULL] but got %#v err=%v", v, err)
+		}
+	})
+	t.Run("not slice", func(t *testing.T) {
+		for _, x := range []interface{}{"", 1, (*[]struct{})(nil), nil} {
+			a := Array(x)
+			if a == nil {
+				t.Fatalf("%#v: expected a scanner reporting the error", x)
+			}
+			if err := a.Scan(&columnDatum{arrayValue("1"), arrayColumn("xs", timestreamquery.ScalarTypeBigint), scanOptions{}}); err == nil {
+				t.Errorf("%#v: expected Scan error", x)
+			}
+			if _, err := a.Value(); err == nil {
+				t.Errorf("%#v: expected Value error", x)
+			}
+		}
+	})
+}
+
+func TestStringArray_Scan(t *testing.T) {
+	null := &timestreamquery.Datum{NullValue: aws.Bool(true)}
+	cases := []struct {
+		name    string
+		src     interface{}
+		want    StringArray
+		wantErr bool
+	}{
+		{"array", &columnDatum{arrayValue("a", "b"), arrayColumn("xs", timestreamquery.ScalarTypeVarchar), scanOptions{}}, StringArray{"a", "b"}, false},
+		{"empty", &columnDatum{arrayValue(), arrayColumn("xs", timestreamquery.ScalarTypeVarchar), scanOptions{}}, StringArray{}, false},
+		{"null element", &columnDatum{&timestreamquery.Datum{ArrayValue: []*timestreamquery.Datum{null}}, arrayColumn("xs", timestreamquery.ScalarTypeVarchar), scanOptions{}}, nil, true},
+		{"not array", &columnDatum{rowDatum(), rowColumn("r"), scanOptions{}}, nil, true},
+		{"bytes", []byte(`["a"]`), nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got StringArray
+			err := got.Scan(c.src)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("wantErr=%v got=%v", c.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("expected=%#v got=%#v", c.want, got)
+			}
+		})
+	}
+}
+
+func BenchmarkFloatArray_Scan(b *testing.B) {
+	r, dest := newArrayBenchmarkRows(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.pos = 0
+		if err := r.Next(dest); err != nil {
+			b.Fatal(err)
+		}
+		var xs FloatArray
+		if err := xs.Scan(dest[0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRows_Next_Array measures the cost of giving an array column to database/sql, that is paid even if it is not scanned by FloatArray.
+func BenchmarkRows_Next_Array(b *testing.B) {
+	r, dest := newArrayBenchmarkRows(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.pos = 0
+		if err := r.Next(dest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func newArrayBenchmarkRows(n int) (*rows, []driver.Value) {
+	values := make([]string, n)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+	r := &rows{
+		rs:   resultSet{columns: []*timestreamquery.ColumnInfo{arrayColumn("xs", timestreamquery.ScalarTypeDouble)}},
+		rows: []*timestreamquery.Row{{Data: []*timestreamquery.Datum{arrayValue(values...)}}},
+	}
+	return r, make([]driver.Value, 1)
+}
+
+func TestArrayOf_Scan(t *testing.T) {
+	ts := "2020-01-02 03:04:05.000000000"
+	null := &timestreamquery.Datum{NullValue: aws.Bool(true)}
+	nested := &timestreamquery.ColumnInfo{Name: aws.String("xs"), Type: &timestreamquery.Type{ArrayColumnInfo: arrayColumn("", timestreamquery.ScalarTypeBigint)}}
+	t.Run("int64", func(t *testing.T) {
+		var got ArrayOf[int64]
+		if err := got.Scan(&columnDatum{arrayValue("1", "-2"), arrayColumn("xs", timestreamquery.ScalarTypeBigint), scanOptions{}}); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (ArrayOf[int64]{1, -2}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, got)
+		}
+	})
+	t.Run("uint64", func(t *testing.T) {
+		var got ArrayOf[uint64]
+		if err := got.Scan(&columnDatum{arrayValue("18446744073709551615"), arrayColumn("xs", timestreamquery.ScalarTypeBigint), scanOptions{}}); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (ArrayOf[uint64]{18446744073709551615}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, got)
+		}
+	})
+	t.Run("negative into uint64", func(t *testing.T) {
+		var got ArrayOf[uint64]
+		if err := got.Scan(&columnDatum{arrayValue("-1"), arrayColumn("xs", timestreamquery.ScalarTypeBigint), scanOptions{}}); err == nil {
+			t.Errorf("expected an error but got %#v", got)
+		}
+	})
+	t.Run("timestamp", func(t *testing.T) {
+		var got ArrayOf[time.Time]
+		if err := got.Scan(&columnDatum{arrayValue(ts), arrayColumn("xs", timestreamquery.ScalarTypeTimestamp), scanOptions{}}); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (ArrayOf[time.Time]{time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, got)
+		}
+	})
+	t.Run("time", func(t *testing.T) {
+		var got ArrayOf[time.Time]
+		if err := got.Scan(&columnDatum{arrayValue("12:34:56.000000001"), arrayColumn("xs", timestreamquery.ScalarTypeTime), scanOptions{}}); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (ArrayOf[time.Time]{time.Date(1, 1, 1, 12, 34, 56, 1, time.UTC)}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, got)
+		}
+	})
+	t.Run("null", func(t *testing.T) {
+		got := ArrayOf[int64]{1}
+		if err := got.Scan(nil); err != nil {
+			t.Fatal(err)
+		}
+		if got != nil {
+			t.Errorf("expected nil but got %#v", got)
+		}
+	})
+	t.Run("nullable", func(t *testing.T) {
+		var got ArrayOf[*float64]
+		src := &columnDatum{&timestreamquery.Datum{ArrayValue: []*timestreamquery.Datum{{ScalarValue: aws.String("0.5")}, null}}, arrayColumn("xs", timestreamquery.ScalarTypeDouble), scanOptions{}}
+		if err := got.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0] == nil || *got[0] != 0.5 || got[1] != nil {
+			t.Errorf("unexpected: %#v", got)
+		}
+	})
+	t.Run("nested", func(t *testing.T) {
+		src := &columnDatum{&timestreamquery.Datum{ArrayValue: []*timestreamquery.Datum{arrayValue("1", "2"), arrayValue()}}, nested, scanOptions{}}
+		var got ArrayOf[ArrayOf[int64]]
+		if err := got.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (ArrayOf[ArrayOf[int64]]{{1, 2}, {}}); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, got)
+		}
+		var slices ArrayOf[[]int]
+		if err := slices.Scan(src); err != nil {
+			t.Fatal(err)
+		}
+		if expected := (ArrayOf[[]int]{{1, 2}, {}}); !reflect.DeepEqual(slices, expected) {
+			t.Errorf("expected=%#v got=%#v", expected, slices)
+		}
+	})
+}
+
+func TestArrayOf_Value(t *testing.T) {
+	one := int64(1)
+	cases := []struct {
+		name string
+		arg  driver.Valuer
+		want string
+	}{
+		{"int64", ArrayOf[int64]{1, 2}, "ARRAY[1,2]"},
+		{"empty", ArrayOf[int64]{}, "ARRAY[]"},
+		{"strings", ArrayOf[string]{"a", "O'Reilly"}, "ARRAY['a','O''Reilly']"},
+		{"nullable", ArrayOf[*int64]{&one, nil}, "ARRAY[1,NULL]"},
+		{"nested", ArrayOf[ArrayOf[int]]{{1}, {2, 3}}, "ARRAY[ARRAY[1],ARRAY[2,3]]"},
+		{"nested slices", ArrayOf[[]bool]{{true}}, "ARRAY[ARRAY[true]]"},
+		{"StringArray", StringArray{"a"}, "ARRAY['a']"},
+		{"BooleanArray", BooleanArray{true, false}, "ARRAY[true,false]"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.arg.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestConn_QueryContext_ArrayParameter(t *testing.T) {
+	db, qr := newRecordingTestDB(t, Config{})
+	rows, err := db.QueryContext(context.Background(), `SELECT contains(?, 1), ?`, ArrayOf[int64]{1, 2}, BareStringValue{"1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if expected := `SELECT contains(ARRAY[1,2], 1), 1h`; qr.last() != expected {
+		t.Errorf("expected=%q got=%q", expected, qr.last())
+	}
+}
@@ -8,6 +8,7 @@ type bareValue interface {
 
 // BareStringValue is a string parameter but not quoted.
 // You can wrap interval literal with this type and then embed interval literal into query.
+// Prefer IntervalDayToSecond and IntervalYearToMonth for intervals, and Ident and QualifiedIdent for names; they are validated and rendered as valid literals.
 type BareStringValue struct {
 	Bare string
 }
@@ -0,0 +1,71 @@
+package timestreamdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrBudgetExceeded is an error indicates the query scanned or metered more bytes than allowed.
+// Use errors.Is() to test errors returned by Rows.Next() or QueryContext(), and errors.As() with *BudgetExceededError to get details.
+var ErrBudgetExceeded = errors.New("query budget exceeded")
+
+// BudgetExceededError is an error returned when the query is cancelled because it scanned or metered more bytes than allowed.
+//
+// Only the limit that is exceeded and the corresponding bytes are set; the others are zero.
+type BudgetExceededError struct {
+	QueryID         string
+	MaxBytesScanned int64
+	BytesScanned    int64
+	MaxBytesMetered int64
+	BytesMetered    int64
+}
+
+func (e *BudgetExceededError) Error() string {
+	if e.MaxBytesMetered > 0 {
+		return fmt.Sprintf("%s: query (%s) metered %d bytes over the limit %d bytes", ErrBudgetExceeded, e.QueryID, e.BytesMetered, e.MaxBytesMetered)
+	}
+	return fmt.Sprintf("%s: query (%s) scanned %d bytes over the limit %d bytes", ErrBudgetExceeded, e.QueryID, e.BytesScanned, e.MaxBytesScanned)
+}
+
+func (e *BudgetExceededError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+type (
+	maxBytesScannedKey struct{}
+	maxBytesMeteredKey struct{}
+)
+
+// WithMaxBytesScanned returns a new context that overrides Config.MaxBytesScanned for queries issued with the context.
+// Zero means unlimited.
+func WithMaxBytesScanned(ctx context.Context, limit int64) context.Context {
+	return context.WithValue(ctx, maxBytesScannedKey{}, limit)
+}
+
+func maxBytesScannedFrom(ctx context.Context) (int64, bool) {
+	limit, ok := ctx.Value(maxBytesScannedKey{}).(int64)
+	return limit, ok
+}
+
+// WithMaxBytesMetered returns a new context that overrides Config.MaxBytesMetered for queries issued with the context.
+// Zero means unlimited.
+func WithMaxBytesMetered(ctx context.Context, limit int64) context.Context {
+	return context.WithValue(ctx, maxBytesMeteredKey{}, limit)
+}
+
+func maxBytesMeteredFrom(ctx context.Context) (int64, bool) {
+	limit, ok := ctx.Value(maxBytesMeteredKey{}).(int64)
+	return limit, ok
+}
+
+// checkBudget returns BudgetExceededError if the stats exceed either limit; zero limits mean unlimited.
+func checkBudget(stats QueryStats, maxBytesScanned, maxBytesMetered int64) error {
+	if maxBytesScanned > 0 && stats.BytesScanned > maxBytesScanned {
+		return &BudgetExceededError{QueryID: stats.QueryID, MaxBytesScanned: maxBytesScanned, BytesScanned: stats.BytesScanned}
+	}
+	if maxBytesMetered > 0 && stats.BytesMetered > maxBytesMetered {
+		return &BudgetExceededError{QueryID: stats.QueryID, MaxBytesMetered: maxBytesMetered, BytesMetered: stats.BytesMetered}
+	}
+	return nil
+}
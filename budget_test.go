@@ -0,0 +1,159 @@
+package timestreamdriver
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestRows_MaxBytesScanned(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}, {"c"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{MaxBytesScanned: 150})
+
+	rows, err := db.QueryContext(context.Background(), `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if expected := []string{"a"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("rows: expected=%#v got=%#v", expected, got)
+	}
+	err = rows.Err()
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded but got %v", err)
+	}
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError but got %T", err)
+	}
+	if expected := (&BudgetExceededError{QueryID: "query-1", MaxBytesScanned: 150, BytesScanned: 200}); !reflect.DeepEqual(budgetErr, expected) {
+		t.Errorf("BudgetExceededError: expected=%#v got=%#v", expected, budgetErr)
+	}
+	if got := ps.canceledQueries(); !reflect.DeepEqual(got, []string{"query-1"}) {
+		t.Errorf("query must be cancelled; canceled=%#v", got)
+	}
+	if got := ps.requestedTokens(); !reflect.DeepEqual(got, []string{"", "1"}) {
+		t.Errorf("no more pages must be fetched after the budget exceeded; requested=%#v", got)
+	}
+}
+
+func TestRows_MaxBytesScanned_FirstPage(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{})
+
+	_, err := db.QueryContext(WithMaxBytesScanned(context.Background(), 50), `SELECT str FROM table1`)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded but got %v", err)
+	}
+	if got := ps.canceledQueries(); !reflect.DeepEqual(got, []string{"query-1"}) {
+		t.Errorf("query must be cancelled; canceled=%#v", got)
+	}
+}
+
+func TestRows_MaxBytesScanned_ContextOverride(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{MaxBytesScanned: 50})
+
+	rows, err := db.QueryContext(WithMaxBytesScanned(context.Background(), 0), `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		t.Errorf("expected no error but got %v", err)
+	}
+}
+
+func TestRows_MaxBytesScanned_LastPage(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{MaxBytesScanned: 150})
+
+	rows, err := db.QueryContext(context.Background(), `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if err := rows.Err(); err != nil {
+		t.Errorf("the last page must be returned without error but got %v", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("rows: expected=%#v got=%#v", expected, got)
+	}
+	if got := ps.canceledQueries(); len(got) != 0 {
+		t.Errorf("finished query must not be cancelled; canceled=%#v", got)
+	}
+}
+
+func TestRows_MaxBytesMetered(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}, {"c"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{MaxBytesMetered: 1500})
+
+	rows, err := db.QueryContext(context.Background(), `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if expected := []string{"a"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("rows: expected=%#v got=%#v", expected, got)
+	}
+	var budgetErr *BudgetExceededError
+	if err := rows.Err(); !errors.As(err, &budgetErr) {
+		t.Fatalf("expected BudgetExceededError but got %v", err)
+	}
+	if expected := (&BudgetExceededError{QueryID: "query-1", MaxBytesMetered: 1500, BytesMetered: 2000}); !reflect.DeepEqual(budgetErr, expected) {
+		t.Errorf("BudgetExceededError: expected=%#v got=%#v", expected, budgetErr)
+	}
+	if got := ps.canceledQueries(); !reflect.DeepEqual(got, []string{"query-1"}) {
+		t.Errorf("query must be cancelled; canceled=%#v", got)
+	}
+}
+
+func TestRows_MaxBytesMetered_ContextOverride(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{})
+
+	_, err := db.QueryContext(WithMaxBytesMetered(context.Background(), 500), `SELECT str FROM table1`)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("expected ErrBudgetExceeded but got %v", err)
+	}
+}
@@ -0,0 +1,271 @@
+package timestreamdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+)
+
+// ErrWriterClosed is an error indicates records are added to BufferedWriter that is already closed.
+var ErrWriterClosed = errors.New("writer closed")
+
+// BufferedWriterOptions configures BufferedWriter. Zero values mean defaults.
+type BufferedWriterOptions struct {
+	// BatchSize is the number of records sent with one WriteRecords call; at most and by default 100.
+	BatchSize int
+	// FlushInterval is the interval to write buffered records even if a batch is not filled; default 1 second.
+	FlushInterval time.Duration
+	// Workers is the number of concurrent WriteRecords calls; default 1.
+	Workers int
+	// BufferSize is the number of records buffered in memory; default 10 batches.
+	// Add blocks while the buffer is full.
+	BufferSize int
+	// OnError is called with the error each time a batch fails to be written.
+	// Records rejected by Timestream are reported as *RejectedRecordsError and put into Writer.DeadLetter.
+	// Records of a batch that failed (e.g. throttled, or aborted by Close) are reported as *UnwrittenRecordsError;
+	// use errors.As to take them for retries, otherwise they are lost.
+	// It is called from workers, concurrently if Workers is more than 1, and may be called after Close returns if Close gives up at the deadline.
+	OnError func(err error)
+}
+
+// BufferedWriter accumulates records in memory and writes them in background.
+//
+// Records are written when a batch is filled or FlushInterval elapsed.
+// Call Flush to wait for buffered records to be written, and Close to flush and stop background goroutines.
+type BufferedWriter struct {
+	w    *Writer
+	opts BufferedWriterOptions
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	in      chan bufferedItem
+	batches chan []*timestreamwrite.Record
+	done    chan struct{}
+	// closing is closed by Close to release Add and Flush blocked on the buffer
+	closing chan struct{}
+
+	// mu guards closed and senders; it is never held while sending to the buffer
+	mu     sync.Mutex
+	closed bool
+	// senders are Add and Flush calls that may send to the buffer; the buffer is closed after they return
+	senders sync.WaitGroup
+
+	errMu    sync.Mutex
+	errs     int
+	firstErr error
+}
+
+type bufferedItem struct {
+	record *timestreamwrite.Record
+	// flushed is non-nil for flush requests; it receives the result of the flush
+	flushed chan error
+}
+
+// NewBufferedWriter returns a new BufferedWriter that writes records with w.
+func NewBufferedWriter(w *Writer, opts BufferedWriterOptions) *BufferedWriter {
+	if opts.BatchSize <= 0 || opts.BatchSize > maxRecordsPerWrite {
+		opts.BatchSize = maxRecordsPerWrite
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = opts.BatchSize * 10
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	bw := &BufferedWriter{
+		w:       w,
+		opts:    opts,
+		ctx:     ctx,
+		cancel:  cancel,
+		in:      make(chan bufferedItem, opts.BufferSize),
+		batches: make(chan []*timestreamwrite.Record),
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	inflight := new(sync.WaitGroup)
+	workers := new(sync.WaitGroup)
+	for i := 0; i < opts.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			bw.work(inflight)
+		}()
+	}
+	go func() {
+		bw.dispatch(inflight)
+		close(bw.batches)
+		workers.Wait()
+		close(bw.done)
+	}()
+	return bw
+}
+
+// Add converts v into records in the same way as Writer.Records and buffers them.
+// It blocks while the buffer is full until ctx is done or the writer is closed.
+// If the writer is closed while Add blocks, ErrWriterClosed is returned and records buffered so far are still written.
+func (bw *BufferedWriter) Add(ctx context.Context, v interface{}) error {
+	records, err := bw.w.Records(v)
+	if err != nil {
+		return err
+	}
+	if err := bw.beginSend(); err != nil {
+		return err
+	}
+	defer bw.senders.Done()
+	for _, record := range records {
+		if err := bw.send(ctx, bufferedItem{record: record}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush waits for records added before the call to be written.
+// It returns an error if some batches failed to be written since the last flush.
+func (bw *BufferedWriter) Flush(ctx context.Context) error {
+	if err := bw.beginSend(); err != nil {
+		return err
+	}
+	flushed := make(chan error, 1)
+	err := bw.send(ctx, bufferedItem{flushed: flushed})
+	bw.senders.Done()
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-flushed:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes buffered records and stops background goroutines.
+// Writes in progress are aborted if ctx is done before they finish, and the records not written are reported to OnError.
+func (bw *BufferedWriter) Close(ctx context.Context) error {
+	bw.mu.Lock()
+	if bw.closed {
+		bw.mu.Unlock()
+		return ErrWriterClosed
+	}
+	bw.closed = true
+	bw.mu.Unlock()
+	close(bw.closing)
+	go func() {
+		// blocked senders return soon as closing is closed; nothing sends to the buffer after they return
+		bw.senders.Wait()
+		close(bw.in)
+	}()
+	select {
+	case <-bw.done:
+	case <-ctx.Done():
+		bw.cancel()
+		return ctx.Err()
+	}
+	bw.cancel()
+	return bw.takeError()
+}
+
+// beginSend registers a sender unless the writer is closed; the caller must call senders.Done.
+func (bw *BufferedWriter) beginSend() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	if bw.closed {
+		return ErrWriterClosed
+	}
+	bw.senders.Add(1)
+	return nil
+}
+
+func (bw *BufferedWriter) send(ctx context.Context, item bufferedItem) error {
+	select {
+	case bw.in <- item:
+		return nil
+	case <-bw.closing:
+		return ErrWriterClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (bw *BufferedWriter) dispatch(inflight *sync.WaitGroup) {
+	ticker := time.NewTicker(bw.opts.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]*timestreamwrite.Record, 0, bw.opts.BatchSize)
+	emit := func() {
+		if len(batch) == 0 {
+			return
+		}
+		inflight.Add(1)
+		bw.batches <- batch
+		batch = make([]*timestreamwrite.Record, 0, bw.opts.BatchSize)
+	}
+	for {
+		select {
+		case item, ok := <-bw.in:
+			if !ok {
+				emit()
+				return
+			}
+			if item.flushed != nil {
+				emit()
+				inflight.Wait()
+				item.flushed <- bw.takeError()
+				continue
+			}
+			batch = append(batch, item.record)
+			if len(batch) >= bw.opts.BatchSize {
+				emit()
+			}
+		case <-ticker.C:
+			emit()
+		}
+	}
+}
+
+func (bw *BufferedWriter) work(inflight *sync.WaitGroup) {
+	for batch := range bw.batches {
+		if _, err := bw.w.write(bw.ctx, batch); err != nil {
+			bw.addError(err)
+		}
+		inflight.Done()
+	}
+}
+
+func (bw *BufferedWriter) addError(err error) {
+	if bw.opts.OnError != nil {
+		bw.opts.OnError(err)
+	}
+	bw.errMu.Lock()
+	defer bw.errMu.Unlock()
+	if bw.firstErr == nil {
+		bw.firstErr = err
+	}
+	bw.errs++
+}
+
+// takeError returns an error that summarizes failures since the last call.
+func (bw *BufferedWriter) takeError() error {
+	bw.errMu.Lock()
+	defer bw.errMu.Unlock()
+	defer func() {
+		bw.errs = 0
+		bw.firstErr = nil
+	}()
+	switch bw.errs {
+	case 0:
+		return nil
+	case 1:
+		return bw.firstErr
+	default:
+		return fmt.Errorf("%d batches failed to be written; first error: %w", bw.errs, bw.firstErr)
+	}
+}
@@ -0,0 +1,202 @@
+package timestreamdriver
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+)
+
+func cpuMetrics(n int) []cpuMetric {
+	metrics := make([]cpuMetric, n)
+	for i := range metrics {
+		metrics[i] = cpuMetric{Time: time.Unix(int64(i), 0), Host: "host-1", Usage: float64(i)}
+	}
+	return metrics
+}
+
+func TestBufferedWriter_Flush(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	bw := NewBufferedWriter(newTestWriter(t, srv), BufferedWriterOptions{FlushInterval: time.Hour, Workers: 3})
+	ctx := context.Background()
+
+	if err := bw.Add(ctx, cpuMetrics(250)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := ws.numRecords(); n != 250 {
+		t.Errorf("expected 250 records written but got %d", n)
+	}
+	ws.mu.Lock()
+	calls := len(ws.inputs)
+	ws.mu.Unlock()
+	if calls != 3 {
+		t.Errorf("expected 3 WriteRecords calls but got %d", calls)
+	}
+
+	if err := bw.Add(ctx, cpuMetrics(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := ws.numRecords(); n != 260 {
+		t.Errorf("expected 260 records written but got %d", n)
+	}
+	if err := bw.Add(ctx, cpuMetrics(1)); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("expected ErrWriterClosed but got %v", err)
+	}
+	if err := bw.Close(ctx); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("expected ErrWriterClosed but got %v", err)
+	}
+}
+
+func TestBufferedWriter_FlushInterval(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	bw := NewBufferedWriter(newTestWriter(t, srv), BufferedWriterOptions{FlushInterval: time.Millisecond * 50})
+	defer bw.Close(context.Background())
+
+	if err := bw.Add(context.Background(), cpuMetrics(1)); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second * 5)
+	for ws.numRecords() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := ws.numRecords(); n != 1 {
+		t.Errorf("expected 1 record written without flush but got %d", n)
+	}
+}
+
+func TestBufferedWriter_Backpressure(t *testing.T) {
+	ws := &writeServer{block: make(chan struct{})}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	bw := NewBufferedWriter(newTestWriter(t, srv), BufferedWriterOptions{BatchSize: 1, BufferSize: 1, FlushInterval: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+	err := bw.Add(ctx, cpuMetrics(10))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Add blocked until deadline but got %v", err)
+	}
+	close(ws.block)
+	if err := bw.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBufferedWriter_CloseWhileAddBlocked(t *testing.T) {
+	ws := &writeServer{block: make(chan struct{})}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	defer close(ws.block)
+	bw := NewBufferedWriter(newTestWriter(t, srv), BufferedWriterOptions{BatchSize: 1, BufferSize: 1, FlushInterval: time.Hour})
+
+	added := make(chan error, 1)
+	go func() {
+		added <- bw.Add(context.Background(), cpuMetrics(10))
+	}()
+	time.Sleep(time.Millisecond * 100)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+	started := time.Now()
+	if err := bw.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Close to give up at the deadline but got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Close must honor the deadline but took %s", elapsed)
+	}
+	select {
+	case err := <-added:
+		if !errors.Is(err, ErrWriterClosed) {
+			t.Errorf("expected ErrWriterClosed but got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Add must be released by Close")
+	}
+}
+
+func TestBufferedWriter_Error(t *testing.T) {
+	ws := &writeServer{fail: true}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	var (
+		mu        sync.Mutex
+		failures  int
+		unwritten []*timestreamwrite.Record
+	)
+	bw := NewBufferedWriter(newTestWriter(t, srv), BufferedWriterOptions{BatchSize: 10, FlushInterval: time.Hour, OnError: func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures++
+		var unwrittenErr *UnwrittenRecordsError
+		if errors.As(err, &unwrittenErr) {
+			unwritten = append(unwritten, unwrittenErr.Unwritten...)
+		}
+	}})
+	ctx := context.Background()
+
+	if err := bw.Add(ctx, cpuMetrics(20)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Flush(ctx); err == nil {
+		t.Error("expected error but got nil")
+	}
+	mu.Lock()
+	if failures != 2 {
+		t.Errorf("expected OnError called 2 times but got %d", failures)
+	}
+	if len(unwritten) != 20 {
+		t.Errorf("expected 20 records reported as unwritten but got %d", len(unwritten))
+	}
+	mu.Unlock()
+	if err := bw.Flush(ctx); err != nil {
+		t.Errorf("errors must be reported once but got %v", err)
+	}
+	if err := bw.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBufferedWriter_CloseDeadline_ReportsUnwritten(t *testing.T) {
+	ws := &writeServer{block: make(chan struct{})}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	defer close(ws.block)
+	var unwritten int32
+	bw := NewBufferedWriter(newTestWriter(t, srv), BufferedWriterOptions{BatchSize: 2, FlushInterval: time.Hour, OnError: func(err error) {
+		var unwrittenErr *UnwrittenRecordsError
+		if errors.As(err, &unwrittenErr) {
+			atomic.AddInt32(&unwritten, int32(len(unwrittenErr.Unwritten)))
+		}
+	}})
+	if err := bw.Add(context.Background(), cpuMetrics(5)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+	defer cancel()
+	if err := bw.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Close to give up at the deadline but got %v", err)
+	}
+	deadline := time.Now().Add(time.Second * 5)
+	for atomic.LoadInt32(&unwritten) < 5 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if n := atomic.LoadInt32(&unwritten); n != 5 {
+		t.Errorf("expected 5 records aborted by Close reported as unwritten but got %d", n)
+	}
+}
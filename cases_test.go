@@ -20,10 +20,10 @@ func testRowsQueryScalar(t *testing.T, rows *sql.Rows) {
 		{name: "percent", databaseTypeName: timestreamquery.ScalarTypeDouble, scanType: reflect.TypeOf(float64(0))},
 		{name: "bool", databaseTypeName: timestreamquery.ScalarTypeBoolean, scanType: reflect.TypeOf(true)},
 		{name: "str", databaseTypeName: timestreamquery.ScalarTypeVarchar, scanType: reflect.TypeOf("")},
-		{name: "dur1", databaseTypeName: timestreamquery.ScalarTypeIntervalDayToSecond, scanType: reflect.TypeOf("")},
-		{name: "dur2", databaseTypeName: timestreamquery.ScalarTypeIntervalYearToMonth, scanType: reflect.TypeOf("")},
+		{name: "dur1", databaseTypeName: timestreamquery.ScalarTypeIntervalDayToSecond, scanType: reflect.TypeOf(IntervalDayToSecond(0))},
+		{name: "dur2", databaseTypeName: timestreamquery.ScalarTypeIntervalYearToMonth, scanType: reflect.TypeOf(IntervalYearToMonth{})},
 		{name: "nullish", databaseTypeName: timestreamquery.ScalarTypeUnknown, scanType: reflect.TypeOf(nil)},
-		{name: "time", databaseTypeName: timestreamquery.ScalarTypeTime, scanType: reflect.TypeOf(time.Time{})},
+		{name: "time", databaseTypeName: timestreamquery.ScalarTypeTime, scanType: reflect.TypeOf(TimeOfDay(0))},
 		{name: "dt", databaseTypeName: timestreamquery.ScalarTypeDate, scanType: reflect.TypeOf(time.Time{})},
 		{name: "ts", databaseTypeName: timestreamquery.ScalarTypeTimestamp, scanType: reflect.TypeOf(time.Time{})},
 		{name: "nullableInt", databaseTypeName: timestreamquery.ScalarTypeInteger, scanType: reflect.TypeOf(int(0))},
@@ -45,7 +45,7 @@ func testRowsQueryScalar(t *testing.T, rows *sql.Rows) {
 			c6  string
 			c7  string
 			c8  interface{}
-			c9  time.Time
+			c9  string
 			c10 time.Time
 			c11 time.Time
 			c12 *int
@@ -71,13 +71,13 @@ func testRowsQueryScalar(t *testing.T, rows *sql.Rows) {
 		if c6 != "0 01:00:00.000000000" {
 			t.Errorf("c6: expected=%v got=%v", "0 01:00:00.000000000", c6)
 		}
-		if c7 != "90 01:00:00.000000000" {
-			t.Errorf("c7: expected=%v got=%v", "90 01:00:00.000000000", c7)
+		if c7 != "1-6" {
+			t.Errorf("c7: expected=%v got=%v", "1-6", c7)
 		}
-		expectedTime := time.Unix(1262349296, 0).UTC()
-		if !expectedTime.Equal(c9) {
-			t.Errorf("c9: expected=%s got=%s", expectedTime, c9)
+		if c9 != "12:34:56.000000000" {
+			t.Errorf("c9: expected=%s got=%s", "12:34:56.000000000", c9)
 		}
+		expectedTime := time.Unix(1262349296, 0).UTC()
 		expectedDate := time.Unix(1262304000, 0).UTC()
 		if !expectedDate.Equal(c10) {
 			t.Errorf("c10: expected=%s got=%s", expectedDate, c10)
@@ -118,9 +118,9 @@ func scalarOutput() *timestreamquery.QueryOutput {
 				{ScalarValue: aws.String("true")},
 				{ScalarValue: aws.String("hi")},
 				{ScalarValue: aws.String("0 01:00:00.000000000")},
-				{ScalarValue: aws.String("90 01:00:00.000000000")},
+				{ScalarValue: aws.String("1-6")},
 				{},
-				{ScalarValue: aws.String("2010-01-01 12:34:56.000000000")},
+				{ScalarValue: aws.String("12:34:56.000000000")},
 				{ScalarValue: aws.String("2010-01-01")},
 				{ScalarValue: aws.String("2010-01-01 12:34:56.000000000")},
 				{NullValue: aws.Bool(true)},
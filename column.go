@@ -0,0 +1,612 @@
+package timestreamdriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+// columnDatum is a value of array, row and timeseries columns.
+//
+// It is given to database/sql as JSON so that it can be scanned into []byte, strings and interface{},
+// and scanners such as ArrayOf, Row and TimeSeries decode the JSON back.
+// The JSON is written and read by hand instead of encoding/json because it is built for every cell of every row.
+type columnDatum struct {
+	datum      *timestreamquery.Datum
+	columnInfo *timestreamquery.ColumnInfo
+	opts       scanOptions
+}
+
+// columnDatumOf returns the value of array, row and timeseries column given to scanners.
+func columnDatumOf(src interface{}) (*columnDatum, error) {
+	switch src := src.(type) {
+	case *columnDatum:
+		return src, nil
+	case []byte:
+		return decodeColumnJSON(src)
+	default:
+		return nil, fmt.Errorf("timestream: cannot convert %T", src)
+	}
+}
+
+// appendColumnJSON appends the column value as JSON like `{"Datum":{...},"ColumnInfo":{...},"Location":"Asia/Tokyo"}`.
+//
+// Datum and ColumnInfo have the same shape as encoding/json gives for timestreamquery types except that nil fields are omitted.
+// Location is the name of the time zone that nested times are converted into; it is omitted for UTC.
+func appendColumnJSON(buf []byte, cd *columnDatum) []byte {
+	buf = append(buf, `{"Datum":`...)
+	buf = appendDatumJSON(buf, cd.datum)
+	buf = append(buf, `,"ColumnInfo":`...)
+	buf = appendColumnInfoJSON(buf, cd.columnInfo)
+	if loc := cd.opts.loc; loc != nil && loc != time.UTC {
+		buf = append(buf, `,"Location":`...)
+		buf = appendJSONString(buf, loc.String())
+	}
+	return append(buf, '}')
+}
+
+func appendDatumJSON(buf []byte, datum *timestreamquery.Datum) []byte {
+	if datum == nil {
+		return append(buf, "null"...)
+	}
+	sep := byte('{')
+	if datum.ScalarValue != nil {
+		buf = appendJSONKey(buf, sep, "ScalarValue")
+		buf = appendJSONString(buf, *datum.ScalarValue)
+		sep = ','
+	}
+	if datum.NullValue != nil {
+		buf = appendJSONKey(buf, sep, "NullValue")
+		buf = appendJSONBool(buf, *datum.NullValue)
+		sep = ','
+	}
+	if datum.ArrayValue != nil {
+		buf = appendJSONKey(buf, sep, "ArrayValue")
+		buf = appendDatumsJSON(buf, datum.ArrayValue)
+		sep = ','
+	}
+	if datum.RowValue != nil {
+		buf = appendJSONKey(buf, sep, "RowValue")
+		buf = appendJSONKey(buf, '{', "Data")
+		buf = appendDatumsJSON(buf, datum.RowValue.Data)
+		buf = append(buf, '}')
+		sep = ','
+	}
+	if datum.TimeSeriesValue != nil {
+		buf = appendJSONKey(buf, sep, "TimeSeriesValue")
+		buf = append(buf, '[')
+		for i, point := range datum.TimeSeriesValue {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if point == nil {
+				buf = append(buf, "null"...)
+				continue
+			}
+			psep := byte('{')
+			if point.Time != nil {
+				buf = appendJSONKey(buf, psep, "Time")
+				buf = appendJSONString(buf, *point.Time)
+				psep = ','
+			}
+			if point.Value != nil {
+				buf = appendJSONKey(buf, psep, "Value")
+				buf = appendDatumJSON(buf, point.Value)
+				psep = ','
+			}
+			buf = closeJSONObject(buf, psep)
+		}
+		buf = append(buf, ']')
+		sep = ','
+	}
+	return closeJSONObject(buf, sep)
+}
+
+func appendDatumsJSON(buf []byte, datums []*timestreamquery.Datum) []byte {
+	buf = append(buf, '[')
+	for i, datum := range datums {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendDatumJSON(buf, datum)
+	}
+	return append(buf, ']')
+}
+
+func appendColumnInfoJSON(buf []byte, columnInfo *timestreamquery.ColumnInfo) []byte {
+	if columnInfo == nil {
+		return append(buf, "null"...)
+	}
+	sep := byte('{')
+	if columnInfo.Name != nil {
+		buf = appendJSONKey(buf, sep, "Name")
+		buf = appendJSONString(buf, *columnInfo.Name)
+		sep = ','
+	}
+	if typ := columnInfo.Type; typ != nil {
+		buf = appendJSONKey(buf, sep, "Type")
+		sep = ','
+		tsep := byte('{')
+		if typ.ScalarType != nil {
+			buf = appendJSONKey(buf, tsep, "ScalarType")
+			buf = appendJSONString(buf, *typ.ScalarType)
+			tsep = ','
+		}
+		if typ.ArrayColumnInfo != nil {
+			buf = appendJSONKey(buf, tsep, "ArrayColumnInfo")
+			buf = appendColumnInfoJSON(buf, typ.ArrayColumnInfo)
+			tsep = ','
+		}
+		if typ.RowColumnInfo != nil {
+			buf = appendJSONKey(buf, tsep, "RowColumnInfo")
+			buf = append(buf, '[')
+			for i, field := range typ.RowColumnInfo {
+				if i > 0 {
+					buf = append(buf, ',')
+				}
+				buf = appendColumnInfoJSON(buf, field)
+			}
+			buf = append(buf, ']')
+			tsep = ','
+		}
+		if typ.TimeSeriesMeasureValueColumnInfo != nil {
+			buf = appendJSONKey(buf, tsep, "TimeSeriesMeasureValueColumnInfo")
+			buf = appendColumnInfoJSON(buf, typ.TimeSeriesMeasureValueColumnInfo)
+			tsep = ','
+		}
+		buf = closeJSONObject(buf, tsep)
+	}
+	return closeJSONObject(buf, sep)
+}
+
+// appendJSONKey appends the separator sep (`{` for the first key, `,` otherwise) and the key.
+func appendJSONKey(buf []byte, sep byte, key string) []byte {
+	buf = append(buf, sep, '"')
+	buf = append(buf, key...)
+	return append(buf, '"', ':')
+}
+
+// closeJSONObject closes the object; sep is still `{` if no keys are appended.
+func closeJSONObject(buf []byte, sep byte) []byte {
+	if sep == '{' {
+		buf = append(buf, '{')
+	}
+	return append(buf, '}')
+}
+
+func appendJSONBool(buf []byte, b bool) []byte {
+	if b {
+		return append(buf, "true"...)
+	}
+	return append(buf, "false"...)
+}
+
+func appendJSONString(buf []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' {
+			continue
+		}
+		buf = append(buf, s[start:i]...)
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		}
+		start = i + 1
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// decodeColumnJSON reads the column value written by appendColumnJSON.
+func decodeColumnJSON(b []byte) (*columnDatum, error) {
+	d := &columnDecoder{s: string(b)}
+	cd := &columnDatum{}
+	var locName string
+	err := d.object(func(key string) error {
+		var err error
+		switch key {
+		case "Datum":
+			cd.datum, err = d.datum()
+		case "ColumnInfo":
+			cd.columnInfo, err = d.columnInfo()
+		case "Location":
+			locName, err = d.string()
+		default:
+			err = d.skip()
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if d.skipSpace(); d.pos != len(d.s) {
+		return nil, d.syntaxError()
+	}
+	if cd.datum == nil || cd.columnInfo == nil || cd.columnInfo.Type == nil {
+		return nil, fmt.Errorf("timestream: invalid column value")
+	}
+	if locName != "" {
+		loc, err := loadLocation(locName)
+		if err != nil {
+			return nil, err
+		}
+		cd.opts.loc = loc
+	}
+	return cd, nil
+}
+
+// locations caches time zones of decoded column values as time.LoadLocation reads the zone database every time.
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// columnDecoder reads JSON of column values.
+//
+// Datums and scalar values are allocated in chunks, growing with the number of decoded datums, because arrays may have many elements.
+type columnDecoder struct {
+	s          string
+	pos        int
+	datumChunk []timestreamquery.Datum
+	valueChunk []string
+	decoded    int
+}
+
+const (
+	minColumnDecoderChunkSize = 16
+	maxColumnDecoderChunkSize = 4096
+)
+
+func (d *columnDecoder) chunkSize() int {
+	switch {
+	case d.decoded < minColumnDecoderChunkSize:
+		return minColumnDecoderChunkSize
+	case d.decoded > maxColumnDecoderChunkSize:
+		return maxColumnDecoderChunkSize
+	default:
+		return d.decoded
+	}
+}
+
+func (d *columnDecoder) newDatum() *timestreamquery.Datum {
+	if len(d.datumChunk) == 0 {
+		d.datumChunk = make([]timestreamquery.Datum, d.chunkSize())
+	}
+	datum := &d.datumChunk[0]
+	d.datumChunk = d.datumChunk[1:]
+	d.decoded++
+	return datum
+}
+
+func (d *columnDecoder) newString(s string) *string {
+	if len(d.valueChunk) == 0 {
+		d.valueChunk = make([]string, d.chunkSize())
+	}
+	p := &d.valueChunk[0]
+	d.valueChunk = d.valueChunk[1:]
+	*p = s
+	return p
+}
+
+func (d *columnDecoder) syntaxError() error {
+	return fmt.Errorf("timestream: invalid column value at offset %d", d.pos)
+}
+
+func (d *columnDecoder) skipSpace() {
+	for d.pos < len(d.s) {
+		switch d.s[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// consume reads c if it is the next character.
+func (d *columnDecoder) consume(c byte) bool {
+	d.skipSpace()
+	if d.pos < len(d.s) && d.s[d.pos] == c {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// literal reads lit such as `null` if it is the next token.
+func (d *columnDecoder) literal(lit string) bool {
+	d.skipSpace()
+	if strings.HasPrefix(d.s[d.pos:], lit) {
+		d.pos += len(lit)
+		return true
+	}
+	return false
+}
+
+// object reads an object calling fn for each key; fn must read the value.
+func (d *columnDecoder) object(fn func(key string) error) error {
+	if !d.consume('{') {
+		return d.syntaxError()
+	}
+	if d.consume('}') {
+		return nil
+	}
+	for {
+		key, err := d.string()
+		if err != nil {
+			return err
+		}
+		if !d.consume(':') {
+			return d.syntaxError()
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+		if d.consume('}') {
+			return nil
+		}
+		if !d.consume(',') {
+			return d.syntaxError()
+		}
+	}
+}
+
+// array reads an array calling fn for each element; fn must read the element.
+func (d *columnDecoder) array(fn func() error) error {
+	if !d.consume('[') {
+		return d.syntaxError()
+	}
+	if d.consume(']') {
+		return nil
+	}
+	for {
+		if err := fn(); err != nil {
+			return err
+		}
+		if d.consume(']') {
+			return nil
+		}
+		if !d.consume(',') {
+			return d.syntaxError()
+		}
+	}
+}
+
+func (d *columnDecoder) string() (string, error) {
+	if !d.consume('"') {
+		return "", d.syntaxError()
+	}
+	start, escaped := d.pos, false
+	for ; d.pos < len(d.s); d.pos++ {
+		switch d.s[d.pos] {
+		case '\\':
+			escaped = true
+			d.pos++
+		case '"':
+			d.pos++
+			if !escaped {
+				return d.s[start : d.pos-1], nil
+			}
+			// escapes are rare; leave them to encoding/json
+			var s string
+			if err := json.Unmarshal([]byte(d.s[start-1:d.pos]), &s); err != nil {
+				return "", err
+			}
+			return s, nil
+		}
+	}
+	return "", d.syntaxError()
+}
+
+func (d *columnDecoder) stringPtr() (*string, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	s, err := d.string()
+	if err != nil {
+		return nil, err
+	}
+	return d.newString(s), nil
+}
+
+func (d *columnDecoder) boolPtr() (*bool, error) {
+	switch {
+	case d.literal("null"):
+		return nil, nil
+	case d.literal("true"):
+		return aws.Bool(true), nil
+	case d.literal("false"):
+		return aws.Bool(false), nil
+	default:
+		return nil, d.syntaxError()
+	}
+}
+
+// skip reads a value of unknown key.
+func (d *columnDecoder) skip() error {
+	d.skipSpace()
+	if d.pos == len(d.s) {
+		return d.syntaxError()
+	}
+	switch d.s[d.pos] {
+	case '{':
+		return d.object(func(string) error { return d.skip() })
+	case '[':
+		return d.array(d.skip)
+	case '"':
+		_, err := d.string()
+		return err
+	}
+	if d.literal("null") || d.literal("true") || d.literal("false") {
+		return nil
+	}
+	start := d.pos
+	for d.pos < len(d.s) && strings.IndexByte("+-.0123456789eE", d.s[d.pos]) >= 0 {
+		d.pos++
+	}
+	if d.pos == start {
+		return d.syntaxError()
+	}
+	return nil
+}
+
+func (d *columnDecoder) datum() (*timestreamquery.Datum, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	datum := d.newDatum()
+	err := d.object(func(key string) error {
+		var err error
+		switch key {
+		case "ScalarValue":
+			datum.ScalarValue, err = d.stringPtr()
+		case "NullValue":
+			datum.NullValue, err = d.boolPtr()
+		case "ArrayValue":
+			datum.ArrayValue, err = d.datums()
+		case "RowValue":
+			datum.RowValue, err = d.row()
+		case "TimeSeriesValue":
+			datum.TimeSeriesValue, err = d.timeSeries()
+		default:
+			err = d.skip()
+		}
+		return err
+	})
+	return datum, err
+}
+
+func (d *columnDecoder) datums() ([]*timestreamquery.Datum, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	datums := []*timestreamquery.Datum{}
+	err := d.array(func() error {
+		datum, err := d.datum()
+		datums = append(datums, datum)
+		return err
+	})
+	return datums, err
+}
+
+func (d *columnDecoder) row() (*timestreamquery.Row, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	row := &timestreamquery.Row{}
+	err := d.object(func(key string) error {
+		if key != "Data" {
+			return d.skip()
+		}
+		var err error
+		row.Data, err = d.datums()
+		return err
+	})
+	return row, err
+}
+
+func (d *columnDecoder) timeSeries() ([]*timestreamquery.TimeSeriesDataPoint, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	points := []*timestreamquery.TimeSeriesDataPoint{}
+	err := d.array(func() error {
+		if d.literal("null") {
+			points = append(points, nil)
+			return nil
+		}
+		point := &timestreamquery.TimeSeriesDataPoint{}
+		points = append(points, point)
+		return d.object(func(key string) error {
+			var err error
+			switch key {
+			case "Time":
+				point.Time, err = d.stringPtr()
+			case "Value":
+				point.Value, err = d.datum()
+			default:
+				err = d.skip()
+			}
+			return err
+		})
+	})
+	return points, err
+}
+
+func (d *columnDecoder) columnInfo() (*timestreamquery.ColumnInfo, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	columnInfo := &timestreamquery.ColumnInfo{}
+	err := d.object(func(key string) error {
+		var err error
+		switch key {
+		case "Name":
+			columnInfo.Name, err = d.stringPtr()
+		case "Type":
+			columnInfo.Type, err = d.typ()
+		default:
+			err = d.skip()
+		}
+		return err
+	})
+	return columnInfo, err
+}
+
+func (d *columnDecoder) typ() (*timestreamquery.Type, error) {
+	if d.literal("null") {
+		return nil, nil
+	}
+	typ := &timestreamquery.Type{}
+	err := d.object(func(key string) error {
+		var err error
+		switch key {
+		case "ScalarType":
+			typ.ScalarType, err = d.stringPtr()
+		case "ArrayColumnInfo":
+			typ.ArrayColumnInfo, err = d.columnInfo()
+		case "RowColumnInfo":
+			if d.literal("null") {
+				return nil
+			}
+			typ.RowColumnInfo = []*timestreamquery.ColumnInfo{}
+			err = d.array(func() error {
+				field, err := d.columnInfo()
+				typ.RowColumnInfo = append(typ.RowColumnInfo, field)
+				return err
+			})
+		case "TimeSeriesMeasureValueColumnInfo":
+			typ.TimeSeriesMeasureValueColumnInfo, err = d.columnInfo()
+		default:
+			err = d.skip()
+		}
+		return err
+	})
+	return typ, err
+}
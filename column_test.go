@@ -0,0 +1,78 @@
+package timestreamdriver
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+func TestColumnJSON(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		cd   *columnDatum
+	}{
+		{"array", &columnDatum{arrayValue("a", "\"quoted\"\\", "line\nbreak\x01", "日本語"), arrayColumn("xs", timestreamquery.ScalarTypeVarchar), scanOptions{}}},
+		{"empty array", &columnDatum{arrayValue(), arrayColumn("xs", timestreamquery.ScalarTypeVarchar), scanOptions{}}},
+		{"null element", &columnDatum{&timestreamquery.Datum{ArrayValue: []*timestreamquery.Datum{nullDatum, scalarDatum("1")}}, arrayColumn("xs", timestreamquery.ScalarTypeBigint), scanOptions{}}},
+		{"row", &columnDatum{
+			rowDatum(scalarDatum("host-1"), rowDatum(scalarDatum("2020-01-02 03:04:05.000000000")), arrayValue("1")),
+			rowColumn("r", scalarColumn("host", timestreamquery.ScalarTypeVarchar), rowColumn("", scalarColumn("ts", timestreamquery.ScalarTypeTimestamp)), arrayColumn("xs", timestreamquery.ScalarTypeBigint)),
+			scanOptions{loc: tokyo},
+		}},
+		{"timeseries", &columnDatum{timeSeriesDatum("2020-01-02 03:04:05.000000000", "0.5"), timeSeriesColumn("cpu", timestreamquery.ScalarTypeDouble), scanOptions{loc: time.UTC}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := appendColumnJSON(nil, c.cd)
+			var v struct {
+				Datum      *timestreamquery.Datum
+				ColumnInfo *timestreamquery.ColumnInfo
+			}
+			if err := json.Unmarshal(b, &v); err != nil {
+				t.Fatalf("invalid JSON: %s: %s", err, b)
+			}
+			if !reflect.DeepEqual(v.Datum, c.cd.datum) || !reflect.DeepEqual(v.ColumnInfo, c.cd.columnInfo) {
+				t.Errorf("encoding/json decoded differently\nexpected: %s %s\n     got: %s %s", c.cd.datum, c.cd.columnInfo, v.Datum, v.ColumnInfo)
+			}
+			got, err := columnDatumOf(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.datum, c.cd.datum) || !reflect.DeepEqual(got.columnInfo, c.cd.columnInfo) {
+				t.Errorf("mismatch\nexpected: %s %s\n     got: %s %s", c.cd.datum, c.cd.columnInfo, got.datum, got.columnInfo)
+			}
+			if got.opts.location().String() != c.cd.opts.location().String() {
+				t.Errorf("location: expected=%s got=%s", c.cd.opts.location(), got.opts.location())
+			}
+		})
+	}
+}
+
+func TestColumnJSON_Invalid(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`[]`,
+		`{"Datum":{"ScalarValue":"1"}}`,
+		`{"Datum":{"ScalarValue":1},"ColumnInfo":{"Type":{}}}`,
+		`{"Datum":{"ArrayValue":[{},]},"ColumnInfo":{"Type":{}}}`,
+		`{"Datum":{},"ColumnInfo":{"Type":{}}} {}`,
+		`{"Datum":{},"ColumnInfo":{"Type":{}},"Location":"Nowhere/Unknown"}`,
+	} {
+		if _, err := columnDatumOf([]byte(src)); err == nil {
+			t.Errorf("%q: expected error", src)
+		}
+	}
+	if cd, err := columnDatumOf([]byte(` { "Unknown" : [1, -2.5e3, true, null, {"a": "b"}], "Datum" : {"ScalarValue": "1"}, "ColumnInfo": {"Type": {"ScalarType": "BIGINT"}} } `)); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	} else if !reflect.DeepEqual(cd.datum, scalarDatum("1")) || aws.StringValue(cd.columnInfo.Type.ScalarType) != "BIGINT" {
+		t.Errorf("unexpected value: %s %s", cd.datum, cd.columnInfo)
+	}
+}
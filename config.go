@@ -4,24 +4,108 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/defaults"
 )
 
 var (
-	keyRegion = "region"
-	keyKeyID  = "accessKeyID"
-	keySecret = "secretAccessKey"
-	keyXray   = "enableXray"
+	keyRegion          = "region"
+	keyKeyID           = "accessKeyID"
+	keySecret          = "secretAccessKey"
+	keyXray            = "enableXray"
+	keyPrefetch        = "prefetch"
+	keyMaxBytesScanned = "maxBytesScanned"
+	keyMaxBytesMetered = "maxBytesMetered"
+	keyLocation        = "loc"
+	keyTimeFormat      = "timeFormat"
+	keyPlaceholder     = "placeholder"
+	keyTable           = "table"
+	keySessionToken    = "sessionToken"
+	keyProfile         = "profile"
+	keySharedConfig    = "sharedConfigFile"
+	keyRoleARN         = "roleARN"
+	keyExternalID      = "externalID"
+	keyRoleSession     = "roleSessionName"
+	keyDuration        = "durationSeconds"
+	keyWebIdentity     = "webIdentityTokenFile"
+	keySTSEndpoint     = "stsEndpoint"
+)
+
+// TimeFormat is a representation of TIMESTAMP, DATE and TIME results.
+type TimeFormat string
+
+const (
+	// TimeFormatTime represents TIMESTAMP and DATE as time.Time and TIME as TimeOfDay. It is the default.
+	TimeFormatTime TimeFormat = "time"
+	// TimeFormatEpochNanos represents TIMESTAMP and DATE as int64 nanoseconds since the Unix epoch and TIME as TimeOfDay.
+	TimeFormatEpochNanos TimeFormat = "epochNanos"
+	// TimeFormatString represents TIMESTAMP, DATE and TIME as strings returned by Timestream.
+	TimeFormatString TimeFormat = "string"
+)
+
+// Placeholder is a style of placeholders in queries.
+type Placeholder string
+
+const (
+	// PlaceholderQuestion is `?` for positional parameters and `$name$` for named parameters. It is the default.
+	PlaceholderQuestion Placeholder = "question"
+	// PlaceholderDollar is `$1`, `$2`, ... that refer parameters by their positions; the same parameter can be referred multiple times.
+	PlaceholderDollar Placeholder = "dollar"
+	// PlaceholderAt is `@name` for named parameters.
+	PlaceholderAt Placeholder = "at"
+	// PlaceholderColon is `:name` for named parameters.
+	PlaceholderColon Placeholder = "colon"
 )
 
 type Config struct {
-	Endpoint           string
-	Region             string
+	Endpoint string
+	Region   string
+	// CredentialProvider provides credentials; if nil, credentials are resolved from the shared config of Profile and SharedConfigFile.
 	CredentialProvider credentials.Provider
-	EnableXray         bool
+	// Profile is the profile of the shared config and credentials files; the default profile if empty.
+	Profile string
+	// SharedConfigFile is the file that the shared config and credentials are loaded from instead of ~/.aws/config and ~/.aws/credentials.
+	SharedConfigFile string
+	// RoleARN is the role to assume with the credentials above; the credentials of the role are used if given.
+	RoleARN string
+	// ExternalID is the external ID to assume the role.
+	ExternalID string
+	// RoleSessionName is the session name of the assumed role; generated if empty.
+	RoleSessionName string
+	// RoleDuration is the duration of the assumed role session; the default of STS if zero.
+	RoleDuration time.Duration
+	// WebIdentityTokenFile is the file of the OIDC token; the role is assumed with the token instead of the credentials if given.
+	WebIdentityTokenFile string
+	// STSEndpoint is the endpoint of STS to assume the role; the endpoint of the region if empty.
+	STSEndpoint string
+	EnableXray  bool
+	// Prefetch enables to fetch the next page of query results in background while the current page is read.
+	Prefetch bool
+	// MaxBytesScanned is the limit of bytes a query may scan; zero means unlimited.
+	// Queries exceeding the limit are cancelled and fail with ErrBudgetExceeded.
+	MaxBytesScanned int64
+	// MaxBytesMetered is the limit of bytes metered (billed) for a query; zero means unlimited.
+	// Queries exceeding the limit are cancelled and fail with ErrBudgetExceeded.
+	MaxBytesMetered int64
+	// Location is the time zone that TIMESTAMP results are converted into and DATE results are interpreted in; UTC if nil.
+	// Location and TimeFormat are given only by `loc` and `timeFormat` of the DSN; there are no connector options for them.
+	Location *time.Location
+	// TimeFormat is the representation of TIMESTAMP, DATE and TIME results; TimeFormatTime if empty.
+	// It applies only to top-level columns; elements of arrays, rows and timeseries are always time.Time and TimeOfDay.
+	TimeFormat TimeFormat
+	// Placeholder is the style of placeholders in queries; PlaceholderQuestion if empty.
+	// Placeholders of other styles are rejected with ErrMixedPlaceholders.
+	Placeholder Placeholder
+	// Database is the default database given by the DSN path; `$__database$` and `$__table$` in queries are expanded with it.
+	// It must follow the naming rules of Timestream in the same way as parts of QualifiedIdent.
+	Database string
+	// Table is the default table; `$__table$` in queries is expanded with it together with Database.
+	// It must follow the naming rules of Timestream in the same way as parts of QualifiedIdent.
+	Table string
 }
 
 func ParseDSN(dsn string) (*Config, error) {
@@ -37,23 +121,118 @@ func ParseDSN(dsn string) (*Config, error) {
 		return nil, err
 	}
 	qs := parsed.Query()
-	cfg := &Config{CredentialProvider: &credentials.ChainProvider{Providers: providers}, EnableXray: qs.Get(keyXray) == "true"}
+	cfg := &Config{CredentialProvider: &credentials.ChainProvider{Providers: providers}, EnableXray: qs.Get(keyXray) == "true", Prefetch: qs.Get(keyPrefetch) == "true"}
 	if region := qs.Get(keyRegion); region != "" {
 		cfg.Region = region
 	}
+	if v := qs.Get(keyMaxBytesScanned); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid %s: %q", keyMaxBytesScanned, v)
+		}
+		cfg.MaxBytesScanned = limit
+	}
+	if v := qs.Get(keyMaxBytesMetered); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid %s: %q", keyMaxBytesMetered, v)
+		}
+		cfg.MaxBytesMetered = limit
+	}
+	if v := qs.Get(keyLocation); v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", keyLocation, err)
+		}
+		cfg.Location = loc
+	}
+	if v := qs.Get(keyTimeFormat); v != "" {
+		switch tf := TimeFormat(v); tf {
+		case TimeFormatTime, TimeFormatEpochNanos, TimeFormatString:
+			cfg.TimeFormat = tf
+		default:
+			return nil, fmt.Errorf("invalid %s: %q", keyTimeFormat, v)
+		}
+	}
+	if v := qs.Get(keyPlaceholder); v != "" {
+		switch p := Placeholder(v); p {
+		case PlaceholderQuestion, PlaceholderDollar, PlaceholderAt, PlaceholderColon:
+			cfg.Placeholder = p
+		default:
+			return nil, fmt.Errorf("invalid %s: %q", keyPlaceholder, v)
+		}
+	}
+	if db := strings.TrimPrefix(parsed.Path, "/"); db != "" {
+		if strings.Contains(db, "/") {
+			return nil, fmt.Errorf("invalid database: %q", db)
+		}
+		if err := validateTableName(db); err != nil {
+			return nil, fmt.Errorf("invalid database: %w", err)
+		}
+		cfg.Database = db
+	}
+	if v := qs.Get(keyTable); v != "" {
+		if err := validateTableName(v); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", keyTable, err)
+		}
+		cfg.Table = v
+	}
 	if endpointHost := parsed.Host; endpointHost != "" {
 		cfg.Endpoint = fmt.Sprintf("%s://%s", scheme, endpointHost)
 	}
-	accessKeyID, secretAccessKey := qs.Get(keyKeyID), qs.Get(keySecret)
-	if accessKeyID != "" && secretAccessKey != "" {
+	accessKeyID, secretAccessKey, sessionToken := qs.Get(keyKeyID), qs.Get(keySecret), qs.Get(keySessionToken)
+	cfg.Profile, cfg.SharedConfigFile = qs.Get(keyProfile), qs.Get(keySharedConfig)
+	switch {
+	case accessKeyID != "" && secretAccessKey != "":
+		if cfg.Profile != "" || cfg.SharedConfigFile != "" {
+			return nil, fmt.Errorf("static credentials cannot be given with %s or %s", keyProfile, keySharedConfig)
+		}
 		cfg.CredentialProvider = &credentials.StaticProvider{Value: credentials.Value{
 			AccessKeyID:     accessKeyID,
 			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
 		}}
+	case accessKeyID != "" || secretAccessKey != "":
+		return nil, fmt.Errorf("both of %s and %s are required for static credentials", keyKeyID, keySecret)
+	case sessionToken != "":
+		return nil, fmt.Errorf("%s requires %s and %s", keySessionToken, keyKeyID, keySecret)
+	case cfg.Profile != "" || cfg.SharedConfigFile != "":
+		cfg.CredentialProvider = nil
+	}
+	if err := parseRole(cfg, qs); err != nil {
+		return nil, err
 	}
 	return cfg, nil
 }
 
+func parseRole(cfg *Config, qs url.Values) error {
+	cfg.RoleARN, cfg.ExternalID, cfg.RoleSessionName = qs.Get(keyRoleARN), qs.Get(keyExternalID), qs.Get(keyRoleSession)
+	cfg.WebIdentityTokenFile, cfg.STSEndpoint = qs.Get(keyWebIdentity), qs.Get(keySTSEndpoint)
+	if v := qs.Get(keyDuration); v != "" {
+		seconds, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || seconds <= 0 {
+			return fmt.Errorf("invalid %s: %q", keyDuration, v)
+		}
+		cfg.RoleDuration = time.Duration(seconds) * time.Second
+	}
+	if cfg.RoleARN == "" {
+		for _, key := range []string{keyExternalID, keyRoleSession, keyDuration, keyWebIdentity, keySTSEndpoint} {
+			if qs.Get(key) != "" {
+				return fmt.Errorf("%s requires %s", key, keyRoleARN)
+			}
+		}
+		return nil
+	}
+	if cfg.WebIdentityTokenFile != "" {
+		for _, key := range []string{keyExternalID, keyKeyID, keySecret, keySessionToken} {
+			if qs.Get(key) != "" {
+				return fmt.Errorf("%s cannot be given with %s", key, keyWebIdentity)
+			}
+		}
+	}
+	return nil
+}
+
 func parseScheme(scheme string) (string, error) {
 	if !strings.Contains(scheme, DriverName) {
 		return "", errors.New("invalid DSN scheme")
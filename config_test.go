@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/defaults"
@@ -12,9 +13,15 @@ import (
 var (
 	defaultProvider *credentials.ChainProvider
 	staticProvider  *credentials.StaticProvider
+	sessionProvider *credentials.StaticProvider
+	tokyo           *time.Location
 )
 
 func init() {
+	var err error
+	if tokyo, err = time.LoadLocation("Asia/Tokyo"); err != nil {
+		panic(err)
+	}
 	df := defaults.Get()
 	defaultProvider = &credentials.ChainProvider{Providers: defaults.CredProviders(df.Config, df.Handlers)}
 	staticProvider = &credentials.StaticProvider{
@@ -23,13 +30,45 @@ func init() {
 			SecretAccessKey: "my-secret",
 		},
 	}
+	sessionProvider = &credentials.StaticProvider{
+		Value: credentials.Value{
+			AccessKeyID:     "my-id",
+			SecretAccessKey: "my-secret",
+			SessionToken:    "my-token",
+		},
+	}
 	dsnConfigAggr = dsnConfigPairAggr{
-		minimal:              dsnConfigPair{"minimal", "awstimestream:///", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider}},
-		customEndpoint:       dsnConfigPair{"custom endpoint", "awstimestream://my.custom.endpoint.example:8000/?region=us-east-1", &Config{Endpoint: "https://my.custom.endpoint.example:8000", Region: "us-east-1", CredentialProvider: defaultProvider}},
-		customSchemeEndpoint: dsnConfigPair{"custom endpoint", "awstimestream+http://insecure.custom.endpoint.example:8000/?region=us-east-1", &Config{Endpoint: "http://insecure.custom.endpoint.example:8000", Region: "us-east-1", CredentialProvider: defaultProvider}},
-		staticCredentials:    dsnConfigPair{"static credentials", "awstimestream:///?region=us-east-1&accessKeyID=my-id&secretAccessKey=my-secret", &Config{Endpoint: "", Region: "us-east-1", CredentialProvider: staticProvider}},
-		xray:                 dsnConfigPair{"minimal", "awstimestream:///?enableXray=true", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, EnableXray: true}},
-		invalidScheme:        dsnConfigPair{"ng/invalid scheme", "http:///", nil},
+		minimal:               dsnConfigPair{"minimal", "awstimestream:///", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider}},
+		customEndpoint:        dsnConfigPair{"custom endpoint", "awstimestream://my.custom.endpoint.example:8000/?region=us-east-1", &Config{Endpoint: "https://my.custom.endpoint.example:8000", Region: "us-east-1", CredentialProvider: defaultProvider}},
+		customSchemeEndpoint:  dsnConfigPair{"custom endpoint", "awstimestream+http://insecure.custom.endpoint.example:8000/?region=us-east-1", &Config{Endpoint: "http://insecure.custom.endpoint.example:8000", Region: "us-east-1", CredentialProvider: defaultProvider}},
+		staticCredentials:     dsnConfigPair{"static credentials", "awstimestream:///?region=us-east-1&accessKeyID=my-id&secretAccessKey=my-secret", &Config{Endpoint: "", Region: "us-east-1", CredentialProvider: staticProvider}},
+		xray:                  dsnConfigPair{"minimal", "awstimestream:///?enableXray=true", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, EnableXray: true}},
+		prefetch:              dsnConfigPair{"prefetch", "awstimestream:///?prefetch=true", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, Prefetch: true}},
+		maxBytesScanned:       dsnConfigPair{"max bytes scanned", "awstimestream:///?maxBytesScanned=1048576", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, MaxBytesScanned: 1048576}},
+		invalidMaxBytes:       dsnConfigPair{"ng/invalid max bytes scanned", "awstimestream:///?maxBytesScanned=1MB", nil},
+		maxBytesMetered:       dsnConfigPair{"max bytes metered", "awstimestream:///?maxBytesMetered=10485760", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, MaxBytesMetered: 10485760}},
+		invalidMaxMetered:     dsnConfigPair{"ng/invalid max bytes metered", "awstimestream:///?maxBytesMetered=-1", nil},
+		location:              dsnConfigPair{"location", "awstimestream:///?loc=Asia%2FTokyo", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, Location: tokyo}},
+		invalidLocation:       dsnConfigPair{"ng/invalid location", "awstimestream:///?loc=Nowhere%2FCity", nil},
+		timeFormat:            dsnConfigPair{"time format", "awstimestream:///?timeFormat=epochNanos", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, TimeFormat: TimeFormatEpochNanos}},
+		invalidTimeFormat:     dsnConfigPair{"ng/invalid time format", "awstimestream:///?timeFormat=rfc3339", nil},
+		placeholder:           dsnConfigPair{"placeholder", "awstimestream:///?placeholder=dollar", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, Placeholder: PlaceholderDollar}},
+		invalidPlaceholder:    dsnConfigPair{"ng/invalid placeholder", "awstimestream:///?placeholder=percent", nil},
+		defaultTable:          dsnConfigPair{"default database and table", "awstimestream:///my-db?table=host.metrics_1", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, Database: "my-db", Table: "host.metrics_1"}},
+		invalidDatabase:       dsnConfigPair{"ng/invalid database", "awstimestream:///mydb/mytable", nil},
+		shortDatabase:         dsnConfigPair{"ng/too short database", "awstimestream:///db", nil},
+		invalidTable:          dsnConfigPair{"ng/invalid table", "awstimestream:///mydb?table=my%20table", nil},
+		sessionToken:          dsnConfigPair{"session token", "awstimestream:///?accessKeyID=my-id&secretAccessKey=my-secret&sessionToken=my-token", &Config{Endpoint: "", Region: "", CredentialProvider: sessionProvider}},
+		partialCredentials:    dsnConfigPair{"ng/partial static credentials", "awstimestream:///?accessKeyID=my-id", nil},
+		onlySessionToken:      dsnConfigPair{"ng/session token without keys", "awstimestream:///?sessionToken=my-token", nil},
+		profile:               dsnConfigPair{"profile", "awstimestream:///?profile=dev&sharedConfigFile=%2Ftmp%2Faws-config", &Config{Endpoint: "", Region: "", Profile: "dev", SharedConfigFile: "/tmp/aws-config"}},
+		profileWithStatic:     dsnConfigPair{"ng/profile with static credentials", "awstimestream:///?profile=dev&accessKeyID=my-id&secretAccessKey=my-secret", nil},
+		role:                  dsnConfigPair{"role", "awstimestream:///?roleARN=arn%3Aaws%3Aiam%3A%3A123456789012%3Arole%2Freader&externalID=ext&roleSessionName=reporter&durationSeconds=3600", &Config{Endpoint: "", Region: "", CredentialProvider: defaultProvider, RoleARN: "arn:aws:iam::123456789012:role/reader", ExternalID: "ext", RoleSessionName: "reporter", RoleDuration: time.Hour}},
+		invalidDuration:       dsnConfigPair{"ng/invalid duration", "awstimestream:///?roleARN=arn&durationSeconds=1h", nil},
+		externalIDWithoutRole: dsnConfigPair{"ng/external ID without role", "awstimestream:///?externalID=ext", nil},
+		webIdentityExternalID: dsnConfigPair{"ng/web identity with external ID", "awstimestream:///?roleARN=arn&webIdentityTokenFile=%2Ftmp%2Ftoken&externalID=ext", nil},
+		webIdentityStatic:     dsnConfigPair{"ng/web identity with static credentials", "awstimestream:///?roleARN=arn&webIdentityTokenFile=%2Ftmp%2Ftoken&accessKeyID=my-id&secretAccessKey=my-secret", nil},
+		invalidScheme:         dsnConfigPair{"ng/invalid scheme", "http:///", nil},
 	}
 }
 
@@ -40,12 +79,37 @@ type dsnConfigPair struct {
 }
 
 type dsnConfigPairAggr struct {
-	minimal              dsnConfigPair
-	customEndpoint       dsnConfigPair
-	customSchemeEndpoint dsnConfigPair
-	staticCredentials    dsnConfigPair
-	xray                 dsnConfigPair
-	invalidScheme        dsnConfigPair
+	minimal               dsnConfigPair
+	customEndpoint        dsnConfigPair
+	customSchemeEndpoint  dsnConfigPair
+	staticCredentials     dsnConfigPair
+	xray                  dsnConfigPair
+	prefetch              dsnConfigPair
+	maxBytesScanned       dsnConfigPair
+	invalidMaxBytes       dsnConfigPair
+	maxBytesMetered       dsnConfigPair
+	invalidMaxMetered     dsnConfigPair
+	location              dsnConfigPair
+	invalidLocation       dsnConfigPair
+	timeFormat            dsnConfigPair
+	invalidTimeFormat     dsnConfigPair
+	placeholder           dsnConfigPair
+	invalidPlaceholder    dsnConfigPair
+	defaultTable          dsnConfigPair
+	invalidDatabase       dsnConfigPair
+	shortDatabase         dsnConfigPair
+	invalidTable          dsnConfigPair
+	sessionToken          dsnConfigPair
+	partialCredentials    dsnConfigPair
+	onlySessionToken      dsnConfigPair
+	profile               dsnConfigPair
+	profileWithStatic     dsnConfigPair
+	role                  dsnConfigPair
+	invalidDuration       dsnConfigPair
+	externalIDWithoutRole dsnConfigPair
+	webIdentityExternalID dsnConfigPair
+	webIdentityStatic     dsnConfigPair
+	invalidScheme         dsnConfigPair
 }
 
 var dsnConfigAggr dsnConfigPairAggr
@@ -60,6 +124,31 @@ func Test_parseDSN(t *testing.T) {
 		{dsnConfigAggr.customSchemeEndpoint, false},
 		{dsnConfigAggr.staticCredentials, false},
 		{dsnConfigAggr.xray, false},
+		{dsnConfigAggr.prefetch, false},
+		{dsnConfigAggr.maxBytesScanned, false},
+		{dsnConfigAggr.invalidMaxBytes, true},
+		{dsnConfigAggr.maxBytesMetered, false},
+		{dsnConfigAggr.invalidMaxMetered, true},
+		{dsnConfigAggr.location, false},
+		{dsnConfigAggr.invalidLocation, true},
+		{dsnConfigAggr.timeFormat, false},
+		{dsnConfigAggr.invalidTimeFormat, true},
+		{dsnConfigAggr.placeholder, false},
+		{dsnConfigAggr.invalidPlaceholder, true},
+		{dsnConfigAggr.defaultTable, false},
+		{dsnConfigAggr.invalidDatabase, true},
+		{dsnConfigAggr.shortDatabase, true},
+		{dsnConfigAggr.invalidTable, true},
+		{dsnConfigAggr.sessionToken, false},
+		{dsnConfigAggr.partialCredentials, true},
+		{dsnConfigAggr.onlySessionToken, true},
+		{dsnConfigAggr.profile, false},
+		{dsnConfigAggr.profileWithStatic, true},
+		{dsnConfigAggr.role, false},
+		{dsnConfigAggr.invalidDuration, true},
+		{dsnConfigAggr.externalIDWithoutRole, true},
+		{dsnConfigAggr.webIdentityExternalID, true},
+		{dsnConfigAggr.webIdentityStatic, true},
 		{dsnConfigAggr.invalidScheme, true},
 	}
 	for _, c := range cases {
@@ -89,6 +178,41 @@ func eqConfig(actual, expected *Config) error {
 	if actual.EnableXray != expected.EnableXray {
 		return fmt.Errorf("EnableXray:\n  actual: %v\nexpected: %v", actual.EnableXray, expected.EnableXray)
 	}
+	if actual.Prefetch != expected.Prefetch {
+		return fmt.Errorf("Prefetch:\n  actual: %v\nexpected: %v", actual.Prefetch, expected.Prefetch)
+	}
+	if actual.MaxBytesScanned != expected.MaxBytesScanned {
+		return fmt.Errorf("MaxBytesScanned:\n  actual: %d\nexpected: %d", actual.MaxBytesScanned, expected.MaxBytesScanned)
+	}
+	if actual.MaxBytesMetered != expected.MaxBytesMetered {
+		return fmt.Errorf("MaxBytesMetered:\n  actual: %d\nexpected: %d", actual.MaxBytesMetered, expected.MaxBytesMetered)
+	}
+	if actual.Location.String() != expected.Location.String() {
+		return fmt.Errorf("Location:\n  actual: %s\nexpected: %s", actual.Location, expected.Location)
+	}
+	if actual.TimeFormat != expected.TimeFormat {
+		return fmt.Errorf("TimeFormat:\n  actual: %s\nexpected: %s", actual.TimeFormat, expected.TimeFormat)
+	}
+	if actual.Placeholder != expected.Placeholder {
+		return fmt.Errorf("Placeholder:\n  actual: %s\nexpected: %s", actual.Placeholder, expected.Placeholder)
+	}
+	if actual.Database != expected.Database {
+		return fmt.Errorf("Database:\n  actual: %s\nexpected: %s", actual.Database, expected.Database)
+	}
+	if actual.Table != expected.Table {
+		return fmt.Errorf("Table:\n  actual: %s\nexpected: %s", actual.Table, expected.Table)
+	}
+	if actual.Profile != expected.Profile {
+		return fmt.Errorf("Profile:\n  actual: %s\nexpected: %s", actual.Profile, expected.Profile)
+	}
+	if actual.SharedConfigFile != expected.SharedConfigFile {
+		return fmt.Errorf("SharedConfigFile:\n  actual: %s\nexpected: %s", actual.SharedConfigFile, expected.SharedConfigFile)
+	}
+	if actual.RoleARN != expected.RoleARN || actual.ExternalID != expected.ExternalID || actual.RoleSessionName != expected.RoleSessionName || actual.RoleDuration != expected.RoleDuration || actual.WebIdentityTokenFile != expected.WebIdentityTokenFile {
+		return fmt.Errorf("Role:\n  actual: %s %s %s %s %s\nexpected: %s %s %s %s %s",
+			actual.RoleARN, actual.ExternalID, actual.RoleSessionName, actual.RoleDuration, actual.WebIdentityTokenFile,
+			expected.RoleARN, expected.ExternalID, expected.RoleSessionName, expected.RoleDuration, expected.WebIdentityTokenFile)
+	}
 	if formatCredProvider(actual.CredentialProvider) != formatCredProvider(expected.CredentialProvider) {
 		return fmt.Errorf("CredentialsProvider:\n  actual: %T\nexpected: %T", actual.CredentialProvider, expected.CredentialProvider)
 	}
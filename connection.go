@@ -6,30 +6,39 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/timestreamquery"
 	"github.com/aws/aws-sdk-go/service/timestreamquery/timestreamqueryiface"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite/timestreamwriteiface"
 )
 
 var (
 	// ErrTooFewParameters is an error indicates number of passed parameters less than query's placeholders.
 	// It may be returned by Rows.QueryContext().
 	ErrTooFewParameters = errors.New("too few parameters passed")
+	// ErrTooManyParameters is an error indicates number of passed parameters more than query's placeholders.
+	// It may be returned by Rows.QueryContext().
+	ErrTooManyParameters = errors.New("too many parameters passed")
+	// ErrMixedPlaceholders is an error indicates the query has placeholders of a style other than Config.Placeholder.
+	ErrMixedPlaceholders = errors.New("placeholders of different styles are mixed")
 
-	placeholder         = '?'
-	namedParamDelimiter = "$"
+	placeholder = '?'
 )
 
 type conn struct {
 	tsq timestreamqueryiface.TimestreamQueryAPI
+	tsw timestreamwriteiface.TimestreamWriteAPI
+	cfg Config
 }
 
 var _ interface {
 	driver.Conn
 	driver.QueryerContext
+	driver.ExecerContext
+	driver.NamedValueChecker
 } = &conn{}
 
 func (conn) Begin() (driver.Tx, error) {
@@ -37,60 +46,97 @@ func (conn) Begin() (driver.Tx, error) {
 }
 
 func (c *conn) Prepare(query string) (driver.Stmt, error) {
-	return &stmt{query: query, cn: c}, nil
+	tmpl, err := parseQuery(query, c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{tmpl: tmpl, cn: c}, nil
 }
 
 func (conn) Close() error {
 	return nil
 }
 
+// CheckNamedValue converts parameters into values that can be embedded into queries.
+// See convertParam for the conversion rules.
+func (conn) CheckNamedValue(nv *driver.NamedValue) error {
+	v, err := convertParam(nv.Value)
+	if err != nil {
+		return err
+	}
+	nv.Value = v
+	return nil
+}
+
 func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
-	return c.queryContext(ctx, query, args)
+	enhancedQuery, err := interpolatesQuery(query, c.cfg, args)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryContext(ctx, enhancedQuery)
 }
 
-func (c *conn) queryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
-	enhancedQuery, err := interpolatesQuery(query, args)
+// ExecContext writes records with Timestream Write API.
+//
+// Only INSERT statements in the form below are supported:
+//
+//	INSERT INTO "db"."table" (time, dimension..., measure_name, measure_value::double) VALUES (?, ?..., ?, ?), ...
+//
+// Columns other than time, measure_name and measure_value::<type> are treated as dimensions.
+// The time is time.Time, a string such as `2020-01-01 00:00:00.000000000` in UTC, or an integer of milliseconds since the Unix epoch;
+// integers of seconds or nanoseconds are not detected and must be converted into milliseconds or time.Time.
+// The result reports the number of ingested records as RowsAffected.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	tmpl, err := parseQuery(query, c.cfg)
 	if err != nil {
 		return nil, err
 	}
-	input := &timestreamquery.QueryInput{QueryString: &enhancedQuery}
-	rows := &rows{rs: resultSet{}}
-	cb := func(out *timestreamquery.QueryOutput, lastPage bool) bool {
-		rows.rs.columns = append(rows.rs.columns, out.ColumnInfo...)
-		rows.rows = append(rows.rows, out.Rows...)
-		return out.NextToken == nil
+	return c.execContext(ctx, tmpl, args)
+}
+
+func (c *conn) execContext(ctx context.Context, tmpl *queryTemplate, args []driver.NamedValue) (driver.Result, error) {
+	stmt, err := parseInsert(tmpl.tokens)
+	if err != nil {
+		return nil, err
 	}
-	if err := c.tsq.QueryPagesWithContext(ctx, input, cb); err != nil {
+	records, err := stmt.records(args)
+	if err != nil {
 		return nil, err
 	}
-	return rows, nil
+	ingested, err := writeRecords(ctx, c.tsw, stmt.database, stmt.table, records)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(ingested), nil
 }
 
-func interpolatesQuery(query string, args []driver.NamedValue) (string, error) {
-	namedParamExpander, err := buildNamedParamExpander(args)
+func (c *conn) queryContext(ctx context.Context, enhancedQuery string) (driver.Rows, error) {
+	rows := newRows(ctx, c.tsq, &timestreamquery.QueryInput{QueryString: &enhancedQuery}, c.cfg)
+	out, err := rows.fetch(nil)
 	if err != nil {
-		return "", err
+		_ = rows.Close()
+		return nil, err
+	}
+	if err := rows.setPage(out); err != nil {
+		_ = rows.Close()
+		return nil, err
 	}
+	return rows, nil
+}
 
-	b := new(bytes.Buffer)
-	placeholderPos := 0
-	for _, v := range query {
-		if v == placeholder {
-			if len(args) < placeholderPos+1 {
-				return "", ErrTooFewParameters
-			}
-			if err := formatParam(b, args[placeholderPos].Value); err != nil {
-				return "", err
-			}
-			placeholderPos++
-		} else {
-			b.WriteRune(v)
-		}
+func interpolatesQuery(query string, cfg Config, args []driver.NamedValue) (string, error) {
+	tmpl, err := parseQuery(query, cfg)
+	if err != nil {
+		return "", err
 	}
-	return namedParamExpander.Replace(b.String()), nil
+	return tmpl.interpolate(args)
 }
 
 func formatParam(buf *bytes.Buffer, val driver.Value) error {
+	if val == nil {
+		buf.WriteString("NULL")
+		return nil
+	}
 	shouldQuote := true
 	if _, ok := val.(bareValue); ok {
 		shouldQuote = false
@@ -110,41 +156,38 @@ func formatParam(buf *bytes.Buffer, val driver.Value) error {
 	case bool:
 		buf.WriteString(fmt.Sprintf("%v", val))
 	case []byte:
-		buf.WriteByte('\'')
-		buf.Write(val)
-		buf.WriteByte('\'')
+		return writeStringLiteral(buf, string(val))
 	case string:
-		if shouldQuote {
-			buf.WriteByte('\'')
-			buf.WriteString(val)
-			buf.WriteByte('\'')
-		} else {
+		if !shouldQuote {
 			buf.WriteString(val)
+			return nil
 		}
+		return writeStringLiteral(buf, val)
 	case time.Time:
-		buf.WriteString(fmt.Sprintf("'%s'", val.Format(tsTimeLayout)))
+		buf.WriteString(fmt.Sprintf("'%s'", val.UTC().Format(tsTimeLayout)))
 	default:
+		if rv := reflect.ValueOf(val); isSliceParam(rv) {
+			return writeListLiteral(buf, rv)
+		}
 		return fmt.Errorf("unknown parameter: %#v (%T)", val, val)
 	}
 	return nil
 }
 
-func buildNamedParamExpander(nvs []driver.NamedValue) (*strings.Replacer, error) {
-	args := []string{}
-	seen := map[string]bool{}
+func formatNamedParams(nvs []driver.NamedValue) (map[string]string, error) {
+	params := map[string]string{}
 	for _, nv := range nvs {
 		if nv.Name == "" {
 			continue
 		}
-		if seen[nv.Name] {
+		if _, seen := params[nv.Name]; seen {
 			return nil, fmt.Errorf("named parameter (%q) appears multiple times", nv.Name)
 		}
-		seen[nv.Name] = true
 		buf := new(bytes.Buffer)
 		if err := formatParam(buf, nv.Value); err != nil {
 			return nil, fmt.Errorf("cannot format parameter: %w", err)
 		}
-		args = append(args, namedParamDelimiter+nv.Name+namedParamDelimiter, buf.String())
+		params[nv.Name] = buf.String()
 	}
-	return strings.NewReplacer(args...), nil
+	return params, nil
 }
@@ -38,7 +38,7 @@ func TestConn_QueryContext_Scalar(t *testing.T) {
 	})))
 
 	ctx := context.Background()
-	db := sql.OpenDB(&connector{tsq})
+	db := sql.OpenDB(&connector{tsq: tsq})
 	rows, err := db.QueryContext(ctx, `SELECT 1 AS num`)
 	if err != nil {
 		t.Fatal(err)
@@ -197,7 +197,7 @@ func TestConn_QueryContext_NamedParams(t *testing.T) {
 	})))
 
 	ctx := context.Background()
-	db := sql.OpenDB(&connector{tsq})
+	db := sql.OpenDB(&connector{tsq: tsq})
 	rows, err := db.QueryContext(ctx, `SELECT age FROM db1.table1 WHERE name = $name$`, sql.Named("name", "yuno"))
 	if err != nil {
 		t.Fatal(err)
@@ -255,7 +255,7 @@ func TestConn_QueryContext_Array(t *testing.T) {
 	})))
 
 	ctx := context.Background()
-	db := sql.OpenDB(&connector{tsq})
+	db := sql.OpenDB(&connector{tsq: tsq})
 	rows, err := db.QueryContext(ctx, `SELECT split('abc/def', '/') AS strs, [1, 2] AS ints, [1.0, 2.0] AS doubles, [true, false] AS bools`)
 	if err != nil {
 		t.Fatal(err)
@@ -321,16 +321,34 @@ func Test_interpolatesQuery(t *testing.T) {
 		{"int parameter", args{"SELECT name FROM db1.table1 WHERE age = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(20)}}}, "SELECT name FROM db1.table1 WHERE age = 20", false},
 		{"string parameter", args{"SELECT age FROM db1.table1 WHERE name = ?", []driver.NamedValue{{Ordinal: 1, Value: "yuno"}}}, "SELECT age FROM db1.table1 WHERE name = 'yuno'", false},
 		{"valuer parameter", args{"SELECT age FROM db1.table1 WHERE name = ?", []driver.NamedValue{{Ordinal: 1, Value: &yuno{}}}}, "SELECT age FROM db1.table1 WHERE name = 'yuno'", false},
+		{"quoted string parameter", args{"SELECT age FROM db1.table1 WHERE name = ?", []driver.NamedValue{{Ordinal: 1, Value: "O'Reilly"}}}, "SELECT age FROM db1.table1 WHERE name = 'O''Reilly'", false},
+		{"bytes parameter", args{"SELECT age FROM db1.table1 WHERE name = ?", []driver.NamedValue{{Ordinal: 1, Value: []byte("' OR 1=1 --")}}}, "SELECT age FROM db1.table1 WHERE name = ''' OR 1=1 --'", false},
 		{"bare parameter", args{"SELECT * FROM db1.table1 WHERE last_login > ago(?)", []driver.NamedValue{{Ordinal: 1, Value: BareStringValue{"7d"}}}}, "SELECT * FROM db1.table1 WHERE last_login > ago(7d)", false},
 
 		{"named/int parameter", args{"SELECT name FROM db1.table1 WHERE age = $age$", []driver.NamedValue{{Name: "age", Ordinal: 1, Value: int64(20)}}}, "SELECT name FROM db1.table1 WHERE age = 20", false},
+		{"named/quoted string parameter", args{"SELECT age FROM db1.table1 WHERE name = $name$", []driver.NamedValue{{Name: "name", Ordinal: 1, Value: "O'Reilly"}}}, "SELECT age FROM db1.table1 WHERE name = 'O''Reilly'", false},
 
+		{"named/reused", args{"SELECT name FROM db1.table1 WHERE age = $age$ OR age = $age$ + 1", []driver.NamedValue{{Name: "age", Ordinal: 1, Value: int64(20)}}}, "SELECT name FROM db1.table1 WHERE age = 20 OR age = 20 + 1", false},
+		{"named/mixed", args{"SELECT name FROM db1.table1 WHERE age = $age$ AND name = ?", []driver.NamedValue{{Name: "age", Ordinal: 1, Value: int64(20)}, {Ordinal: 2, Value: "yuno"}}}, "SELECT name FROM db1.table1 WHERE age = 20 AND name = 'yuno'", false},
+
+		{"placeholder in string literal", args{"SELECT * FROM db1.table1 WHERE regexp_like(name, 'a?') AND age = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(20)}}}, "SELECT * FROM db1.table1 WHERE regexp_like(name, 'a?') AND age = 20", false},
+		{"named placeholder in string literal", args{"SELECT * FROM db1.table1 WHERE name = '$age$' AND age = $age$", []driver.NamedValue{{Name: "age", Ordinal: 1, Value: int64(20)}}}, "SELECT * FROM db1.table1 WHERE name = '$age$' AND age = 20", false},
+		{"placeholder in quoted identifier", args{`SELECT "what?" FROM db1.table1 WHERE age = ?`, []driver.NamedValue{{Ordinal: 1, Value: int64(20)}}}, `SELECT "what?" FROM db1.table1 WHERE age = 20`, false},
+		{"placeholder in comments", args{"SELECT * -- why?\nFROM db1.table1 /* $age$ ? */ WHERE age = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(20)}}}, "SELECT * -- why?\nFROM db1.table1 /* $age$ ? */ WHERE age = 20", false},
+		{"parameter including placeholders", args{"SELECT * FROM db1.table1 WHERE name = ? AND age = ?", []driver.NamedValue{{Ordinal: 1, Value: "?"}, {Ordinal: 2, Value: int64(20)}}}, "SELECT * FROM db1.table1 WHERE name = '?' AND age = 20", false},
+
+		{"unterminated string literal", args{"SELECT * FROM db1.table1 WHERE name = 'yuno", []driver.NamedValue{}}, "", true},
 		{"less parameters", args{"SELECT name FROM db1.table1 WHERE age = ?", []driver.NamedValue{}}, "", true},
-		{"unhandleable parameters", args{"SELECT name FROM db1.table1 WHERE age = ?", []driver.NamedValue{{Ordinal: 1, Value: []string{"hi"}}}}, "", true},
+		{"control character", args{"SELECT name FROM db1.table1 WHERE name = ?", []driver.NamedValue{{Ordinal: 1, Value: "a\x00"}}}, "", true},
+		{"named/invalid UTF-8", args{"SELECT name FROM db1.table1 WHERE name = $name$", []driver.NamedValue{{Name: "name", Ordinal: 1, Value: "\xff"}}}, "", true},
+		{"named/less parameters", args{"SELECT name FROM db1.table1 WHERE age = $age$", []driver.NamedValue{}}, "", true},
+		{"more parameters", args{"SELECT name FROM db1.table1 WHERE age = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(20)}, {Ordinal: 2, Value: int64(21)}}}, "", true},
+		{"named/more parameters", args{"SELECT name FROM db1.table1 WHERE age = ?", []driver.NamedValue{{Ordinal: 1, Value: int64(20)}, {Name: "age", Ordinal: 2, Value: int64(21)}}}, "", true},
+		{"unhandleable parameters", args{"SELECT name FROM db1.table1 WHERE age = ?", []driver.NamedValue{{Ordinal: 1, Value: map[string]int{"hi": 1}}}}, "", true},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			got, err := interpolatesQuery(c.args.query, c.args.args)
+			got, err := interpolatesQuery(c.args.query, Config{}, c.args.args)
 			if (err != nil) != c.wantErr {
 				t.Errorf("wantErr=%v err=%v", c.wantErr, err)
 				return
@@ -453,3 +471,42 @@ var _ sampling.Strategy = alwaysSample(0)
 func (alwaysSample) ShouldTrace(r *sampling.Request) *sampling.Decision {
 	return &sampling.Decision{Sample: true}
 }
+
+// queryRecorder responds empty results and records query strings.
+type queryRecorder struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (qr *queryRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var input *timestreamquery.QueryInput
+	_ = json.NewDecoder(r.Body).Decode(&input)
+	qr.mu.Lock()
+	qr.queries = append(qr.queries, aws.StringValue(input.QueryString))
+	qr.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(&timestreamquery.QueryOutput{Rows: []*timestreamquery.Row{}})
+}
+
+func (qr *queryRecorder) last() string {
+	qr.mu.Lock()
+	defer qr.mu.Unlock()
+	if len(qr.queries) == 0 {
+		return ""
+	}
+	return qr.queries[len(qr.queries)-1]
+}
+
+func newRecordingTestDB(t *testing.T, cfg Config) (*sql.DB, *queryRecorder) {
+	t.Helper()
+	qr := &queryRecorder{}
+	srv := httptest.NewServer(qr)
+	t.Cleanup(srv.Close)
+	tsq := timestreamquery.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+	return sql.OpenDB(&connector{tsq: tsq, cfg: cfg}), qr
+}
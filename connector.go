@@ -5,14 +5,17 @@ import (
 	"database/sql/driver"
 
 	"github.com/aws/aws-sdk-go/service/timestreamquery/timestreamqueryiface"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite/timestreamwriteiface"
 )
 
 type connector struct {
 	tsq timestreamqueryiface.TimestreamQueryAPI
+	tsw timestreamwriteiface.TimestreamWriteAPI
+	cfg Config
 }
 
 func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
-	return &conn{c.tsq}, nil
+	return &conn{tsq: c.tsq, tsw: c.tsw, cfg: c.cfg}, nil
 }
 
 func (connector) Driver() driver.Driver {
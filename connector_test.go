@@ -21,7 +21,7 @@ func TestConnector_Driver(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			connector := &connector{c.fields.tsq}
+			connector := &connector{tsq: c.fields.tsq}
 			if got := connector.Driver(); !reflect.DeepEqual(got, c.want) {
 				t.Errorf("Connector.Driver() = %v, want %v", got, c.want)
 			}
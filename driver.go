@@ -7,8 +7,11 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/aws/aws-sdk-go/service/timestreamquery"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
 	"github.com/aws/aws-xray-sdk-go/xray"
 )
 
@@ -36,22 +39,63 @@ func (d *Driver) OpenConnector(dsn string) (driver.Connector, error) {
 	if err != nil {
 		return nil, err
 	}
-	awsCfg := aws.Config{Credentials: credentials.NewCredentials(cfg.CredentialProvider)}
+	ses, err := newSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tsq := timestreamquery.New(ses)
+	tsw := timestreamwrite.New(ses)
+	return &connector{tsq: tsq, tsw: tsw, cfg: *cfg}, nil
+}
+
+// newSession builds AWS session with the region, endpoint and credentials given by Config.
+func newSession(cfg *Config) (*session.Session, error) {
+	awsCfg := aws.Config{}
+	if cfg.CredentialProvider != nil {
+		awsCfg.Credentials = credentials.NewCredentials(cfg.CredentialProvider)
+	}
 	if cfg.Region != "" {
 		awsCfg.Region = &cfg.Region
 	}
 	if cfg.Endpoint != "" {
 		awsCfg.Endpoint = aws.String(cfg.Endpoint)
 	}
-	ses, err := session.NewSessionWithOptions(session.Options{Config: awsCfg})
+	opts := session.Options{Config: awsCfg, Profile: cfg.Profile}
+	if cfg.Profile != "" || cfg.SharedConfigFile != "" {
+		opts.SharedConfigState = session.SharedConfigEnable
+	}
+	if cfg.SharedConfigFile != "" {
+		opts.SharedConfigFiles = []string{cfg.SharedConfigFile}
+	}
+	ses, err := session.NewSessionWithOptions(opts)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.RoleARN != "" {
+		ses = ses.Copy(&aws.Config{Credentials: credentials.NewCredentials(roleProvider(ses, cfg))})
+	}
 	if cfg.EnableXray {
 		ses = xray.AWSSession(ses)
 	}
-	tsq := timestreamquery.New(ses)
-	return &connector{tsq}, nil
+	return ses, nil
+}
+
+// roleProvider returns the provider of the credentials of Config.RoleARN assumed with the credentials of the session,
+// or with the web identity token if Config.WebIdentityTokenFile is given.
+func roleProvider(ses *session.Session, cfg *Config) credentials.Provider {
+	// the endpoint of the session is of Timestream; reset it to resolve the endpoint of STS of the region
+	stsCfg := &aws.Config{Endpoint: aws.String(cfg.STSEndpoint)}
+	client := sts.New(ses, stsCfg)
+	if cfg.WebIdentityTokenFile != "" {
+		return stscreds.NewWebIdentityRoleProviderWithOptions(client, cfg.RoleARN, cfg.RoleSessionName, stscreds.FetchTokenPath(cfg.WebIdentityTokenFile), func(p *stscreds.WebIdentityRoleProvider) {
+			p.Duration = cfg.RoleDuration
+		})
+	}
+	p := &stscreds.AssumeRoleProvider{Client: client, RoleARN: cfg.RoleARN, RoleSessionName: cfg.RoleSessionName, Duration: cfg.RoleDuration}
+	if cfg.ExternalID != "" {
+		p.ExternalID = aws.String(cfg.ExternalID)
+	}
+	return p
 }
 
 var _ interface {
@@ -1,8 +1,23 @@
 package timestreamdriver
 
 import (
+	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/sts"
 )
 
 func TestDriver_Open(t *testing.T) {
@@ -26,3 +41,216 @@ func TestDriver_Open(t *testing.T) {
 		})
 	}
 }
+
+func Test_newSession_SharedConfig(t *testing.T) {
+	for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(key, "")
+	}
+	path := filepath.Join(t.TempDir(), "config")
+	content := `[default]
+aws_access_key_id = default-id
+aws_secret_access_key = default-secret
+
+[profile dev]
+region = ap-northeast-1
+aws_access_key_id = dev-id
+aws_secret_access_key = dev-secret
+aws_session_token = dev-token
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ParseDSN("awstimestream:///?profile=dev&sharedConfigFile=" + url.QueryEscape(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ses, err := newSession(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if region := aws.StringValue(ses.Config.Region); region != "ap-northeast-1" {
+		t.Errorf("unexpected region: %s", region)
+	}
+	creds, err := ses.Config.Credentials.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.AccessKeyID != "dev-id" || creds.SecretAccessKey != "dev-secret" || creds.SessionToken != "dev-token" {
+		t.Errorf("unexpected credentials: %#v", creds)
+	}
+}
+
+// stsServer is a stand-in of STS that issues credentials named after the action.
+type stsServer struct {
+	mu    sync.Mutex
+	forms []url.Values
+}
+
+func (s *stsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.forms = append(s.forms, r.PostForm)
+	s.mu.Unlock()
+	action := r.PostForm.Get("Action")
+	w.Header().Set("Content-Type", "text/xml")
+	fmt.Fprintf(w, `<%[1]sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <%[1]sResult>
+    <Credentials>
+      <AccessKeyId>%[1]s-id</AccessKeyId>
+      <SecretAccessKey>%[1]s-secret</SecretAccessKey>
+      <SessionToken>%[1]s-token</SessionToken>
+      <Expiration>%[2]s</Expiration>
+    </Credentials>
+    <AssumedRoleUser>
+      <Arn>%[3]s/session</Arn>
+      <AssumedRoleId>AROA:session</AssumedRoleId>
+    </AssumedRoleUser>
+  </%[1]sResult>
+  <ResponseMetadata><RequestId>request-1</RequestId></ResponseMetadata>
+</%[1]sResponse>`, action, time.Now().Add(time.Hour).UTC().Format(time.RFC3339), r.PostForm.Get("RoleArn"))
+}
+
+func (s *stsServer) lastForm() url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.forms) == 0 {
+		return nil
+	}
+	return s.forms[len(s.forms)-1]
+}
+
+func Test_newSession_AssumeRole(t *testing.T) {
+	for _, key := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE", "AWS_ROLE_ARN", "AWS_WEB_IDENTITY_TOKEN_FILE"} {
+		t.Setenv(key, "")
+	}
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("oidc-token"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ss := &stsServer{}
+	srv := httptest.NewServer(ss)
+	defer srv.Close()
+	role := "arn:aws:iam::123456789012:role/reader"
+
+	cases := []struct {
+		name      string
+		params    url.Values
+		wantKeyID string
+		wantForm  url.Values
+	}{
+		{
+			"assume role",
+			url.Values{keyKeyID: {"base-id"}, keySecret: {"base-secret"}, keyExternalID: {"ext-1"}, keyRoleSession: {"reporter"}, keyDuration: {"1800"}},
+			"AssumeRole-id",
+			url.Values{"Action": {"AssumeRole"}, "RoleArn": {role}, "ExternalId": {"ext-1"}, "RoleSessionName": {"reporter"}, "DurationSeconds": {"1800"}},
+		},
+		{
+			"web identity",
+			url.Values{keyWebIdentity: {tokenFile}, keyRoleSession: {"reporter"}},
+			"AssumeRoleWithWebIdentity-id",
+			url.Values{"Action": {"AssumeRoleWithWebIdentity"}, "RoleArn": {role}, "RoleSessionName": {"reporter"}, "WebIdentityToken": {"oidc-token"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params := url.Values{keyRegion: {"us-east-1"}, keyRoleARN: {role}, keySTSEndpoint: {srv.URL}}
+			for k, v := range c.params {
+				params[k] = v
+			}
+			cfg, err := ParseDSN("awstimestream:///?" + params.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ses, err := newSession(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			creds, err := ses.Config.Credentials.Get()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds.AccessKeyID != c.wantKeyID {
+				t.Errorf("unexpected credentials: %#v", creds)
+			}
+			form := ss.lastForm()
+			for k := range c.wantForm {
+				if form.Get(k) != c.wantForm.Get(k) {
+					t.Errorf("%s: expected=%q got=%q", k, c.wantForm.Get(k), form.Get(k))
+				}
+			}
+		})
+	}
+}
+
+func TestDriver_Open_AssumeRole(t *testing.T) {
+	ss := &stsServer{}
+	stsSrv := httptest.NewServer(ss)
+	defer stsSrv.Close()
+	var authorization string
+	tsSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(scalarOutput())
+	}))
+	defer tsSrv.Close()
+
+	params := url.Values{
+		keyRegion:      {"us-east-1"},
+		keyKeyID:       {"base-id"},
+		keySecret:      {"base-secret"},
+		keyRoleARN:     {"arn:aws:iam::123456789012:role/reader"},
+		keySTSEndpoint: {stsSrv.URL},
+	}
+	db, err := sql.Open(DriverName, "awstimestream+http://"+strings.TrimPrefix(tsSrv.URL, "http://")+"/?"+params.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if !strings.Contains(authorization, "Credential=AssumeRole-id/") {
+		t.Errorf("query is not signed with the assumed role: %s", authorization)
+	}
+	if form := ss.lastForm(); form.Get("Action") != "AssumeRole" {
+		t.Errorf("unexpected STS request: %v", form)
+	}
+}
+
+func Test_roleProvider_Endpoint(t *testing.T) {
+	cases := []struct {
+		name        string
+		stsEndpoint string
+		want        string
+	}{
+		{"regional", "", "https://sts.amazonaws.com"},
+		{"custom", "http://sts.example", "http://sts.example"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params := url.Values{keyRegion: {"us-east-1"}, keyKeyID: {"base-id"}, keySecret: {"base-secret"}, keyRoleARN: {"arn:aws:iam::123456789012:role/reader"}}
+			if c.stsEndpoint != "" {
+				params.Set(keySTSEndpoint, c.stsEndpoint)
+			}
+			cfg, err := ParseDSN("awstimestream+http://timestream.example/?" + params.Encode())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ses, err := newSession(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := roleProvider(ses, cfg).(*stscreds.AssumeRoleProvider)
+			if got := p.Client.(*sts.STS).Endpoint; got != c.want {
+				t.Errorf("STS endpoint: expected=%s got=%s", c.want, got)
+			}
+			if got := aws.StringValue(ses.Config.Endpoint); got != "http://timestream.example" {
+				t.Errorf("Timestream endpoint: expected=%s got=%s", "http://timestream.example", got)
+			}
+		})
+	}
+}
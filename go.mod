@@ -1,8 +1,13 @@
 module github.com/aereal/go-aws-timestream-driver
 
-go 1.15
+go 1.18
 
 require (
-	github.com/aws/aws-sdk-go v1.35.28
+	github.com/aws/aws-sdk-go v1.44.100
 	github.com/aws/aws-xray-sdk-go v1.1.0
 )
+
+require (
+	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+)
@@ -0,0 +1,122 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidIdent is an error indicates an identifier parameter violates the naming rules.
+var ErrInvalidIdent = errors.New("invalid identifier")
+
+const (
+	// maxIdentLength is the maximum length in bytes of database, table and column names.
+	maxIdentLength = 256
+	// minTableNameLength is the minimum length of database and table names.
+	minTableNameLength = 3
+)
+
+// Ident is an identifier parameter such as a database, table or column name.
+//
+// It is rendered as a double-quoted identifier such as `"tenant_db"`; double quotes in the name are doubled.
+// Names must be valid UTF-8 of 1 to 256 bytes without control characters.
+type Ident string
+
+var _ interface {
+	driver.Valuer
+	bareValue
+} = Ident("")
+
+func (i Ident) Value() (driver.Value, error) {
+	buf := new(bytes.Buffer)
+	if err := writeIdent(buf, string(i)); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+func (Ident) IsBareValue() {}
+
+// QualifiedIdent is a multi-part identifier parameter such as `QualifiedIdent{"tenant_db", "metrics"}`.
+//
+// It is rendered as dot-separated identifiers such as `"tenant_db"."metrics"`.
+// Each part follows the naming rules of databases and tables: 3 to 256 characters of letters, digits, `_`, `.` and `-`.
+type QualifiedIdent []string
+
+var _ interface {
+	driver.Valuer
+	bareValue
+} = QualifiedIdent{}
+
+func (qi QualifiedIdent) Value() (driver.Value, error) {
+	if len(qi) == 0 {
+		return nil, fmt.Errorf("%w: empty qualified identifier", ErrInvalidIdent)
+	}
+	buf := new(bytes.Buffer)
+	for i, part := range qi {
+		if i > 0 {
+			buf.WriteByte('.')
+		}
+		if err := validateTableName(part); err != nil {
+			return nil, err
+		}
+		if err := writeIdent(buf, part); err != nil {
+			return nil, err
+		}
+	}
+	return buf.String(), nil
+}
+
+func (QualifiedIdent) IsBareValue() {}
+
+func writeIdent(buf *bytes.Buffer, name string) error {
+	if err := validateIdent(name); err != nil {
+		return err
+	}
+	buf.WriteByte('"')
+	for _, r := range name {
+		if r == '"' {
+			buf.WriteString(`""`)
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('"')
+	return nil
+}
+
+func validateIdent(name string) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty name", ErrInvalidIdent)
+	}
+	if len(name) > maxIdentLength {
+		return fmt.Errorf("%w: %q is longer than %d bytes", ErrInvalidIdent, name, maxIdentLength)
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("%w: invalid UTF-8 sequence in %q", ErrInvalidIdent, name)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: control character %U in %q", ErrInvalidIdent, r, name)
+		}
+	}
+	return nil
+}
+
+// validateTableName validates the name against the naming rules of databases and tables of Timestream.
+func validateTableName(name string) error {
+	if len(name) < minTableNameLength || len(name) > maxIdentLength {
+		return fmt.Errorf("%w: %q must be %d to %d characters", ErrInvalidIdent, name, minTableNameLength, maxIdentLength)
+	}
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_', c == '.', c == '-':
+		default:
+			return fmt.Errorf("%w: %q has a character other than letters, digits, _, . and -", ErrInvalidIdent, name)
+		}
+	}
+	return nil
+}
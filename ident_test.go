@@ -0,0 +1,63 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_interpolatesQuery_Ident(t *testing.T) {
+	cases := []struct {
+		name    string
+		arg     interface{}
+		want    string
+		wantErr error
+	}{
+		{"ident", Ident("metrics"), `SELECT * FROM "metrics"`, nil},
+		{"qualified", QualifiedIdent{"tenant_db", "metrics"}, `SELECT * FROM "tenant_db"."metrics"`, nil},
+		{"quotes are escaped", Ident(`a"; DROP`), `SELECT * FROM "a""; DROP"`, nil},
+		{"unicode", Ident("メトリクス"), `SELECT * FROM "メトリクス"`, nil},
+		{"empty", Ident(""), ``, ErrInvalidIdent},
+		{"empty qualified", QualifiedIdent{}, ``, ErrInvalidIdent},
+		{"empty part", QualifiedIdent{"tenant_db", ""}, ``, ErrInvalidIdent},
+		{"too short part", QualifiedIdent{"a", "metrics"}, ``, ErrInvalidIdent},
+		{"space in part", QualifiedIdent{"tenant_db", "my table"}, ``, ErrInvalidIdent},
+		{"quote in part", QualifiedIdent{"tenant_db", `a"b`}, ``, ErrInvalidIdent},
+		{"allowed characters in parts", QualifiedIdent{"tenant-db.v1", "Metrics_2"}, `SELECT * FROM "tenant-db.v1"."Metrics_2"`, nil},
+		{"too long", Ident(strings.Repeat("a", 257)), ``, ErrInvalidIdent},
+		{"control character", Ident("a\nb"), ``, ErrInvalidIdent},
+		{"invalid UTF-8", Ident("\xff"), ``, ErrInvalidIdent},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := interpolatesQuery(`SELECT * FROM ?`, Config{}, []driver.NamedValue{{Ordinal: 1, Value: c.arg}})
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("expected error %v but got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestConn_QueryContext_IdentParameter(t *testing.T) {
+	db, qr := newRecordingTestDB(t, Config{})
+	rows, err := db.QueryContext(context.Background(), `SELECT ? FROM $table$`, Ident("cpu"), sql.Named("table", QualifiedIdent{"tenant_db", "metrics"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if expected := `SELECT "cpu" FROM "tenant_db"."metrics"`; qr.last() != expected {
+		t.Errorf("expected=%q got=%q", expected, qr.last())
+	}
+}
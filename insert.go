@@ -0,0 +1,509 @@
+package timestreamdriver
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+)
+
+var (
+	// ErrUnsupportedStatement is an error indicates the statement cannot be executed by Exec.
+	// Only INSERT statements are supported.
+	ErrUnsupportedStatement = errors.New("unsupported statement")
+
+	columnTime         = "time"
+	columnMeasureName  = "measure_name"
+	columnMeasureValue = "measure_value"
+)
+
+// insertStatement is a parsed INSERT statement:
+//
+//	INSERT INTO "db"."table" (time, dimension..., measure_name, measure_value::type) VALUES (...), ...
+//
+// Columns other than time, measure_name and measure_value::<type> are treated as dimensions.
+type insertStatement struct {
+	database string
+	table    string
+	columns  []insertColumn
+	rows     [][]token
+}
+
+type insertColumn struct {
+	name string
+	// measureValueType is a type of the measure value; only set for measure_value column
+	measureValueType string
+}
+
+// parseInsert parses tokens as INSERT statement.
+func parseInsert(tokens []token) (*insertStatement, error) {
+	p := &insertParser{tokens: significantTokens(tokens)}
+	return p.parse()
+}
+
+func significantTokens(tokens []token) []token {
+	ret := make([]token, 0, len(tokens))
+	for _, tok := range tokens {
+		if tok.kind == tokenWhitespace || tok.kind == tokenComment {
+			continue
+		}
+		ret = append(ret, tok)
+	}
+	return ret
+}
+
+type insertParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *insertParser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *insertParser) errorf(format string, args ...interface{}) error {
+	at := "end of statement"
+	if tok := p.peek(); tok != nil {
+		at = fmt.Sprintf("%q at %d", tok.text, tok.pos)
+	}
+	return fmt.Errorf("cannot parse INSERT statement: %s near %s", fmt.Sprintf(format, args...), at)
+}
+
+func (p *insertParser) acceptKeyword(keyword string) bool {
+	tok := p.peek()
+	if tok == nil || tok.kind != tokenIdent || !strings.EqualFold(tok.text, keyword) {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *insertParser) acceptPunct(punct string) bool {
+	tok := p.peek()
+	if tok == nil || tok.kind != tokenPunct || tok.text != punct {
+		return false
+	}
+	p.pos++
+	return true
+}
+
+func (p *insertParser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.errorf("expected %s", keyword)
+	}
+	return nil
+}
+
+func (p *insertParser) expectPunct(punct string) error {
+	if !p.acceptPunct(punct) {
+		return p.errorf("expected %q", punct)
+	}
+	return nil
+}
+
+func (p *insertParser) name() (string, error) {
+	tok := p.peek()
+	if tok == nil {
+		return "", p.errorf("expected name")
+	}
+	switch tok.kind {
+	case tokenIdent:
+		p.pos++
+		return tok.text, nil
+	case tokenQuotedIdent:
+		p.pos++
+		return unquoteIdent(tok.text), nil
+	default:
+		return "", p.errorf("expected name")
+	}
+}
+
+func (p *insertParser) parse() (*insertStatement, error) {
+	if !p.acceptKeyword("INSERT") {
+		return nil, fmt.Errorf("%w: only INSERT statements can be executed", ErrUnsupportedStatement)
+	}
+	if err := p.expectKeyword("INTO"); err != nil {
+		return nil, err
+	}
+	stmt := &insertStatement{}
+	var err error
+	if stmt.database, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err := p.expectPunct("."); err != nil {
+		return nil, err
+	}
+	if stmt.table, err = p.name(); err != nil {
+		return nil, err
+	}
+	if stmt.columns, err = p.columns(); err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("VALUES"); err != nil {
+		return nil, err
+	}
+	for {
+		row, err := p.values()
+		if err != nil {
+			return nil, err
+		}
+		if len(row) != len(stmt.columns) {
+			return nil, fmt.Errorf("cannot parse INSERT statement: %d values given for %d columns", len(row), len(stmt.columns))
+		}
+		stmt.rows = append(stmt.rows, row)
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+	p.acceptPunct(";")
+	if p.peek() != nil {
+		return nil, p.errorf("unexpected token")
+	}
+	if err := stmt.validate(); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+func (p *insertParser) columns() ([]insertColumn, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var cols []insertColumn
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		col := insertColumn{name: name}
+		if p.acceptPunct("::") {
+			typ, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			col.measureValueType = strings.ToUpper(typ)
+		}
+		cols = append(cols, col)
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	return cols, nil
+}
+
+func (p *insertParser) values() ([]token, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var row []token
+	for {
+		tok := p.peek()
+		if tok == nil {
+			return nil, p.errorf("expected value")
+		}
+		switch tok.kind {
+		case tokenString, tokenNumber, tokenIdent, tokenPositionalParam, tokenNamedParam, tokenOrdinalParam:
+			row = append(row, *tok)
+			p.pos++
+		case tokenPunct:
+			if tok.text != "-" {
+				return nil, p.errorf("expected value")
+			}
+			p.pos++
+			num := p.peek()
+			if num == nil || num.kind != tokenNumber {
+				return nil, p.errorf("expected number")
+			}
+			row = append(row, token{kind: tokenNumber, text: "-" + num.text, pos: tok.pos})
+			p.pos++
+		default:
+			return nil, p.errorf("expected value")
+		}
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+	if err := p.expectPunct(")"); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (s *insertStatement) validate() error {
+	var hasTime, hasMeasureName, hasMeasureValue bool
+	for _, col := range s.columns {
+		switch {
+		case col.name == columnTime:
+			hasTime = true
+		case col.name == columnMeasureName:
+			hasMeasureName = true
+		case col.name == columnMeasureValue:
+			if hasMeasureValue {
+				return fmt.Errorf("%s column appears multiple times", columnMeasureValue)
+			}
+			switch col.measureValueType {
+			case timestreamwrite.MeasureValueTypeDouble, timestreamwrite.MeasureValueTypeBigint, timestreamwrite.MeasureValueTypeVarchar, timestreamwrite.MeasureValueTypeBoolean, timestreamwrite.MeasureValueTypeTimestamp:
+			default:
+				return fmt.Errorf("%s column must have one of types: double, bigint, varchar, boolean, timestamp (e.g. %s::double)", columnMeasureValue, columnMeasureValue)
+			}
+			hasMeasureValue = true
+		case col.measureValueType != "":
+			return fmt.Errorf("type is given to the column (%s) other than %s", col.name, columnMeasureValue)
+		}
+	}
+	if !hasTime || !hasMeasureName || !hasMeasureValue {
+		return fmt.Errorf("INSERT statement must have columns: %s, %s and %s::<type>", columnTime, columnMeasureName, columnMeasureValue)
+	}
+	return nil
+}
+
+// records builds records that the values are bound with args.
+func (s *insertStatement) records(args []driver.NamedValue) ([]*timestreamwrite.Record, error) {
+	binder, err := newArgBinder(s.rows, args)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*timestreamwrite.Record, len(s.rows))
+	for i, row := range s.rows {
+		record := &timestreamwrite.Record{}
+		for j, col := range s.columns {
+			val, err := binder.bind(row[j])
+			if err != nil {
+				return nil, err
+			}
+			if err := setRecordField(record, col, val); err != nil {
+				return nil, fmt.Errorf("row #%d: %w", i+1, err)
+			}
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+func setRecordField(record *timestreamwrite.Record, col insertColumn, val driver.Value) error {
+	switch col.name {
+	case columnTime:
+		if val == nil {
+			return fmt.Errorf("%s must not be NULL", columnTime)
+		}
+		t, unit, err := formatRecordTime(val)
+		if err != nil {
+			return err
+		}
+		record.Time = aws.String(t)
+		record.TimeUnit = aws.String(unit)
+	case columnMeasureName:
+		if val == nil {
+			return fmt.Errorf("%s must not be NULL", columnMeasureName)
+		}
+		name, err := formatRecordValue(val)
+		if err != nil {
+			return err
+		}
+		record.MeasureName = aws.String(name)
+	case columnMeasureValue:
+		if val == nil {
+			return fmt.Errorf("%s must not be NULL", columnMeasureValue)
+		}
+		v, err := formatRecordValue(val)
+		if err != nil {
+			return err
+		}
+		record.MeasureValue = aws.String(v)
+		record.MeasureValueType = aws.String(col.measureValueType)
+	default:
+		if val == nil {
+			return nil
+		}
+		v, err := formatRecordValue(val)
+		if err != nil {
+			return err
+		}
+		record.Dimensions = append(record.Dimensions, &timestreamwrite.Dimension{
+			Name:               aws.String(col.name),
+			Value:              aws.String(v),
+			DimensionValueType: aws.String(timestreamwrite.DimensionValueTypeVarchar),
+		})
+	}
+	return nil
+}
+
+// formatRecordTime formats time.Time as epoch nanoseconds, integers as epoch milliseconds
+// and strings in the Timestream timestamp format (e.g. `2020-01-01 00:00:00.000000000`) as epoch nanoseconds.
+// Integers are always taken as milliseconds; the unit of seconds or nanoseconds cannot be told from the value.
+func formatRecordTime(val driver.Value) (string, string, error) {
+	switch val := val.(type) {
+	case time.Time:
+		return strconv.FormatInt(val.UnixNano(), 10), timestreamwrite.TimeUnitNanoseconds, nil
+	case int64:
+		return strconv.FormatInt(val, 10), timestreamwrite.TimeUnitMilliseconds, nil
+	case string:
+		parsed, err := time.ParseInLocation(tsTimeLayout, val, time.UTC)
+		if err != nil {
+			return "", "", fmt.Errorf("cannot parse %s: %w", columnTime, err)
+		}
+		return strconv.FormatInt(parsed.UnixNano(), 10), timestreamwrite.TimeUnitNanoseconds, nil
+	default:
+		return "", "", fmt.Errorf("cannot use %T as %s", val, columnTime)
+	}
+}
+
+func formatRecordValue(val driver.Value) (string, error) {
+	switch val := val.(type) {
+	case int64:
+		return strconv.FormatInt(val, 10), nil
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(val), nil
+	case []byte:
+		return string(val), nil
+	case string:
+		return val, nil
+	case time.Time:
+		return strconv.FormatInt(val.UnixNano()/int64(time.Millisecond), 10), nil
+	default:
+		return "", fmt.Errorf("unknown parameter: %#v (%T)", val, val)
+	}
+}
+
+// argBinder resolves value tokens of INSERT statement into values.
+type argBinder struct {
+	positional []driver.NamedValue
+	named      map[string]driver.Value
+	pos        int
+}
+
+func newArgBinder(rows [][]token, args []driver.NamedValue) (*argBinder, error) {
+	b := &argBinder{named: map[string]driver.Value{}}
+	for _, arg := range args {
+		if arg.Name == "" {
+			b.positional = append(b.positional, arg)
+			continue
+		}
+		if _, seen := b.named[arg.Name]; seen {
+			return nil, fmt.Errorf("named parameter (%q) appears multiple times", arg.Name)
+		}
+		b.named[arg.Name] = arg.Value
+	}
+	numPositional, maxOrdinal := 0, 0
+	names := map[string]bool{}
+	for _, row := range rows {
+		for _, tok := range row {
+			switch tok.kind {
+			case tokenPositionalParam:
+				numPositional++
+			case tokenOrdinalParam:
+				if tok.ordinal > maxOrdinal {
+					maxOrdinal = tok.ordinal
+				}
+			case tokenNamedParam:
+				names[tok.name] = true
+			}
+		}
+	}
+	if len(b.positional) > numPositional+maxOrdinal {
+		return nil, fmt.Errorf("statement has %d placeholders but %d parameters passed: %w", numPositional+maxOrdinal, len(b.positional), ErrTooManyParameters)
+	}
+	for name := range b.named {
+		if !names[name] {
+			return nil, fmt.Errorf("named parameter (%q) is not used in the statement: %w", name, ErrTooManyParameters)
+		}
+	}
+	return b, nil
+}
+
+func (b *argBinder) bind(tok token) (driver.Value, error) {
+	var val driver.Value
+	switch tok.kind {
+	case tokenPositionalParam:
+		if len(b.positional) < b.pos+1 {
+			return nil, ErrTooFewParameters
+		}
+		val = b.positional[b.pos].Value
+		b.pos++
+	case tokenOrdinalParam:
+		if len(b.positional) < tok.ordinal {
+			return nil, fmt.Errorf("parameter %s not passed: %w", tok.text, ErrTooFewParameters)
+		}
+		val = b.positional[tok.ordinal-1].Value
+	case tokenNamedParam:
+		v, ok := b.named[tok.name]
+		if !ok {
+			return nil, fmt.Errorf("named parameter (%q) not passed: %w", tok.name, ErrTooFewParameters)
+		}
+		val = v
+	case tokenString:
+		return unquoteString(tok.text), nil
+	case tokenNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number: %s", tok.text)
+		}
+		return f, nil
+	case tokenIdent:
+		switch strings.ToUpper(tok.text) {
+		case "TRUE":
+			return true, nil
+		case "FALSE":
+			return false, nil
+		case "NULL":
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unexpected value: %s", tok.text)
+	}
+	return recordValue(val)
+}
+
+// recordValue unwraps parameter types rendered as SQL literals into values of records,
+// e.g. IntervalDayToSecond (and time.Duration) into int64 nanoseconds and Ident into the name.
+func recordValue(val driver.Value) (driver.Value, error) {
+	switch v := val.(type) {
+	case TimeValue:
+		return v.Time, nil
+	case IntervalDayToSecond:
+		return int64(v), nil
+	case IntervalYearToMonth:
+		return v.String(), nil
+	case TimeOfDay:
+		return v.String(), nil
+	case Ident:
+		return string(v), nil
+	case numericLiteral:
+		return string(v), nil
+	case BareStringValue:
+		return v.Bare, nil
+	case SliceValue, QualifiedIdent:
+		return nil, fmt.Errorf("cannot write %T as a record value", val)
+	}
+	if isSliceParam(reflect.ValueOf(val)) {
+		return nil, fmt.Errorf("cannot write %T as a record value", val)
+	}
+	if valuer, ok := val.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return val, nil
+}
+
+func unquoteString(text string) string {
+	return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+}
+
+func unquoteIdent(text string) string {
+	return strings.ReplaceAll(text[1:len(text)-1], `""`, `"`)
+}
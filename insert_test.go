@@ -0,0 +1,299 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+)
+
+func Test_parseInsert(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   string
+		want    *insertStatement
+		wantErr bool
+	}{
+		{
+			"ok",
+			`INSERT INTO "db1"."table 1" (time, host, measure_name, measure_value::double) VALUES (?, 'host-1', 'cpu', -0.5), ($t$, ?, ?, ?);`,
+			&insertStatement{
+				database: "db1",
+				table:    "table 1",
+				columns:  []insertColumn{{name: "time"}, {name: "host"}, {name: "measure_name"}, {name: "measure_value", measureValueType: "DOUBLE"}},
+			},
+			false,
+		},
+		{"select", "SELECT 1", nil, true},
+		{"no table", "INSERT INTO db1 (time) VALUES (?)", nil, true},
+		{"no measure value", "INSERT INTO db1.table1 (time, measure_name) VALUES (?, ?)", nil, true},
+		{"untyped measure value", "INSERT INTO db1.table1 (time, measure_name, measure_value) VALUES (?, ?, ?)", nil, true},
+		{"unknown measure value type", "INSERT INTO db1.table1 (time, measure_name, measure_value::text) VALUES (?, ?, ?)", nil, true},
+		{"typed dimension", "INSERT INTO db1.table1 (time, host::varchar, measure_name, measure_value::bigint) VALUES (?, ?, ?, ?)", nil, true},
+		{"values mismatch", "INSERT INTO db1.table1 (time, measure_name, measure_value::bigint) VALUES (?, ?)", nil, true},
+		{"trailing tokens", "INSERT INTO db1.table1 (time, measure_name, measure_value::bigint) VALUES (?, ?, ?) RETURNING 1", nil, true},
+		{"expression", "INSERT INTO db1.table1 (time, measure_name, measure_value::bigint) VALUES (now(), ?, ?)", nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokens, err := lex(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseInsert(tokens)
+			if (err != nil) != c.wantErr {
+				t.Errorf("wantErr=%v err=%v", c.wantErr, err)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.database != c.want.database || got.table != c.want.table || !reflect.DeepEqual(got.columns, c.want.columns) {
+				t.Errorf("mismatch\nexpected: %#v\n     got: %#v", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_insertStatement_records(t *testing.T) {
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC)
+	cases := []struct {
+		name    string
+		query   string
+		args    []driver.NamedValue
+		want    []*timestreamwrite.Record
+		wantErr bool
+	}{
+		{
+			"literals",
+			`INSERT INTO db1.table1 (time, host, region, measure_name, measure_value::bigint) VALUES ('2020-01-02 03:04:05.000000006', 'it''s', NULL, 'count', 42)`,
+			nil,
+			[]*timestreamwrite.Record{
+				{
+					Time: aws.String("1577934245000000006"), TimeUnit: aws.String("NANOSECONDS"),
+					Dimensions:  []*timestreamwrite.Dimension{{Name: aws.String("host"), Value: aws.String("it's"), DimensionValueType: aws.String("VARCHAR")}},
+					MeasureName: aws.String("count"), MeasureValue: aws.String("42"), MeasureValueType: aws.String("BIGINT"),
+				},
+			},
+			false,
+		},
+		{
+			"parameters",
+			`INSERT INTO db1.table1 (time, host, measure_name, measure_value::boolean) VALUES (?, $host$, ?, ?), (?, $host$, ?, TRUE)`,
+			[]driver.NamedValue{
+				{Ordinal: 1, Value: ts},
+				{Name: "host", Ordinal: 2, Value: "host-1"},
+				{Ordinal: 3, Value: "up"},
+				{Ordinal: 4, Value: false},
+				{Ordinal: 5, Value: int64(1577934245000)},
+				{Ordinal: 6, Value: "up"},
+			},
+			[]*timestreamwrite.Record{
+				{
+					Time: aws.String("1577934245000000006"), TimeUnit: aws.String("NANOSECONDS"),
+					Dimensions:  []*timestreamwrite.Dimension{{Name: aws.String("host"), Value: aws.String("host-1"), DimensionValueType: aws.String("VARCHAR")}},
+					MeasureName: aws.String("up"), MeasureValue: aws.String("false"), MeasureValueType: aws.String("BOOLEAN"),
+				},
+				{
+					Time: aws.String("1577934245000"), TimeUnit: aws.String("MILLISECONDS"),
+					Dimensions:  []*timestreamwrite.Dimension{{Name: aws.String("host"), Value: aws.String("host-1"), DimensionValueType: aws.String("VARCHAR")}},
+					MeasureName: aws.String("up"), MeasureValue: aws.String("true"), MeasureValueType: aws.String("BOOLEAN"),
+				},
+			},
+			false,
+		},
+		{"too few parameters", `INSERT INTO db1.table1 (time, measure_name, measure_value::double) VALUES (?, ?, ?)`, []driver.NamedValue{{Ordinal: 1, Value: ts}}, nil, true},
+		{"too many parameters", `INSERT INTO db1.table1 (time, measure_name, measure_value::double) VALUES (?, 'm', 1.0)`, []driver.NamedValue{{Ordinal: 1, Value: ts}, {Ordinal: 2, Value: ts}}, nil, true},
+		{"null measure value", `INSERT INTO db1.table1 (time, measure_name, measure_value::double) VALUES (?, 'm', NULL)`, []driver.NamedValue{{Ordinal: 1, Value: ts}}, nil, true},
+		{"invalid time", `INSERT INTO db1.table1 (time, measure_name, measure_value::double) VALUES ('yesterday', 'm', 1.0)`, nil, nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokens, err := lex(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmt, err := parseInsert(tokens)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := stmt.records(c.args)
+			if (err != nil) != c.wantErr {
+				t.Errorf("wantErr=%v err=%v", c.wantErr, err)
+				return
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("mismatch\nexpected: %s\n     got: %s", c.want, got)
+			}
+		})
+	}
+}
+
+// writeServer is a stand-in of Timestream Write API that records inputs of WriteRecords.
+type writeServer struct {
+	// block makes the server wait before responding until it is closed
+	block chan struct{}
+	// fail makes the server respond ValidationException
+	fail bool
+	// reject makes the server respond RejectedRecordsException for records that have the measure value
+	reject string
+	// failFrom makes the server respond InternalServerException from the request of the 1-based ordinal, if given
+	failFrom int
+
+	mu       sync.Mutex
+	inputs   []*timestreamwrite.WriteRecordsInput
+	requests int
+}
+
+func (s *writeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if target := r.Header.Get("X-Amz-Target"); target != "Timestream_20181101.WriteRecords" {
+		http.Error(w, fmt.Sprintf("unexpected target: %s", target), http.StatusBadRequest)
+		return
+	}
+	var input *timestreamwrite.WriteRecordsInput
+	_ = json.NewDecoder(r.Body).Decode(&input)
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	s.requests++
+	failing := s.failFrom > 0 && s.requests >= s.failFrom
+	s.mu.Unlock()
+	if failing {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"__type":"InternalServerException","message":"internal error"}`))
+		return
+	}
+	if s.fail {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"ValidationException","message":"invalid records"}`))
+		return
+	}
+	s.mu.Lock()
+	s.inputs = append(s.inputs, input)
+	s.mu.Unlock()
+	if s.reject != "" {
+		var rejected []*timestreamwrite.RejectedRecord
+		for i, record := range input.Records {
+			if aws.StringValue(record.MeasureValue) == s.reject {
+				rejected = append(rejected, &timestreamwrite.RejectedRecord{
+					RecordIndex:     aws.Int64(int64(i)),
+					Reason:          aws.String("A record already exists with the same time, dimensions, measure name."),
+					ExistingVersion: aws.Int64(1),
+				})
+			}
+		}
+		if len(rejected) > 0 {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"__type": "RejectedRecordsException", "message": "records rejected", "RejectedRecords": rejected})
+			return
+		}
+	}
+	n := int64(len(input.Records))
+	_ = json.NewEncoder(w).Encode(&timestreamwrite.WriteRecordsOutput{RecordsIngested: &timestreamwrite.RecordsIngested{Total: &n}})
+}
+
+func (s *writeServer) numRecords() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, input := range s.inputs {
+		n += len(input.Records)
+	}
+	return n
+}
+
+func newWriteTestDB(srv *httptest.Server) *sql.DB {
+	return sql.OpenDB(&connector{tsw: newWriteTestClient(srv)})
+}
+
+func newWriteTestClient(srv *httptest.Server) *timestreamwrite.TimestreamWrite {
+	return timestreamwrite.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+}
+
+func TestConn_ExecContext(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	db := newWriteTestDB(srv)
+
+	ctx := context.Background()
+	st, err := db.PrepareContext(ctx, `INSERT INTO "db1"."table1" (time, host, measure_name, measure_value::double) VALUES (?, ?, 'cpu', ?)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	res, err := st.ExecContext(ctx, time.Unix(1, 0), "host-1", 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 1 {
+		t.Errorf("RowsAffected: expected=1 got=%d", n)
+	}
+
+	query := `INSERT INTO db1.table1 (time, measure_name, measure_value::bigint) VALUES `
+	args := []interface{}{}
+	for i := 0; i < 150; i++ {
+		if i > 0 {
+			query += ", "
+		}
+		query += "(?, 'count', ?)"
+		args = append(args, time.Unix(int64(i), 0), int64(i))
+	}
+	res, err = db.ExecContext(ctx, query, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := res.RowsAffected(); n != 150 {
+		t.Errorf("RowsAffected: expected=150 got=%d", n)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.inputs) != 3 {
+		t.Fatalf("expected 3 WriteRecords calls but got %d", len(ws.inputs))
+	}
+	for i, expected := range []int{1, 100, 50} {
+		input := ws.inputs[i]
+		if aws.StringValue(input.DatabaseName) != "db1" || aws.StringValue(input.TableName) != "table1" {
+			t.Errorf("#%d: unexpected table: %s.%s", i, aws.StringValue(input.DatabaseName), aws.StringValue(input.TableName))
+		}
+		if len(input.Records) != expected {
+			t.Errorf("#%d: expected %d records but got %d", i, expected, len(input.Records))
+		}
+	}
+}
+
+func TestConn_ExecContext_Unsupported(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	db := newWriteTestDB(srv)
+
+	_, err := db.ExecContext(context.Background(), `DELETE FROM db1.table1`)
+	if !errors.Is(err, ErrUnsupportedStatement) {
+		t.Errorf("expected ErrUnsupportedStatement but got %v", err)
+	}
+}
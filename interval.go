@@ -0,0 +1,255 @@
+package timestreamdriver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNegativeInterval is an error indicates a negative interval is passed as a parameter.
+	ErrNegativeInterval = errors.New("negative interval cannot be rendered as a literal")
+
+	day = 24 * time.Hour
+
+	intervalUnits = []struct {
+		unit     time.Duration
+		notation string
+	}{
+		{day, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+		{time.Millisecond, "ms"},
+		{time.Microsecond, "us"},
+		{time.Nanosecond, "ns"},
+	}
+)
+
+// IntervalDayToSecond is a value of INTERVAL_DAY_TO_SECOND.
+//
+// It is rendered as a duration literal in the largest unit that represents it exactly (e.g. `1h`, `90m`, `1500ms`) as a parameter,
+// INTERVAL_DAY_TO_SECOND columns can be scanned into this type, time.Duration and strings (the text as returned by Timestream).
+type IntervalDayToSecond time.Duration
+
+var _ interface {
+	driver.Valuer
+	sql.Scanner
+	bareValue
+	fmt.Stringer
+} = new(IntervalDayToSecond)
+
+func (d IntervalDayToSecond) Value() (driver.Value, error) {
+	if d < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNegativeInterval, time.Duration(d))
+	}
+	if d == 0 {
+		return "0s", nil
+	}
+	for _, u := range intervalUnits {
+		if time.Duration(d)%u.unit == 0 {
+			return strconv.FormatInt(int64(time.Duration(d)/u.unit), 10) + u.notation, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot render interval: %s", time.Duration(d))
+}
+
+func (IntervalDayToSecond) IsBareValue() {}
+
+// String returns the interval in the form of Timestream results such as `1 02:03:04.000000000`.
+func (d IntervalDayToSecond) String() string {
+	sign := ""
+	abs := time.Duration(d)
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+	days := abs / day
+	abs -= days * day
+	hours := abs / time.Hour
+	abs -= hours * time.Hour
+	minutes := abs / time.Minute
+	abs -= minutes * time.Minute
+	seconds := abs / time.Second
+	abs -= seconds * time.Second
+	return fmt.Sprintf("%s%d %02d:%02d:%02d.%09d", sign, days, hours, minutes, seconds, abs)
+}
+
+func (d *IntervalDayToSecond) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case IntervalDayToSecond:
+		*d = src
+		return nil
+	case intervalResult:
+		parsed, err := parseIntervalDayToSecond(string(src))
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	case int64:
+		*d = IntervalDayToSecond(src)
+		return nil
+	case string:
+		parsed, err := parseIntervalDayToSecond(src)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+		return nil
+	default:
+		return fmt.Errorf("timestream: cannot convert %T into IntervalDayToSecond", src)
+	}
+}
+
+// parseIntervalDayToSecond parses the form of `D HH:MM:SS.NNNNNNNNN`.
+func parseIntervalDayToSecond(s string) (IntervalDayToSecond, error) {
+	invalid := fmt.Errorf("timestream: invalid interval day to second: %q", s)
+	body := s
+	negative := strings.HasPrefix(body, "-")
+	if negative {
+		body = body[1:]
+	}
+	daysPart, clock, ok := strings.Cut(body, " ")
+	if !ok {
+		return 0, invalid
+	}
+	days, err := strconv.ParseInt(daysPart, 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+	hms := strings.Split(clock, ":")
+	if len(hms) != 3 {
+		return 0, invalid
+	}
+	hours, err := strconv.ParseInt(hms[0], 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+	minutes, err := strconv.ParseInt(hms[1], 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+	secPart, fracPart, _ := strings.Cut(hms[2], ".")
+	seconds, err := strconv.ParseInt(secPart, 10, 64)
+	if err != nil {
+		return 0, invalid
+	}
+	var nanos int64
+	if fracPart != "" {
+		if len(fracPart) > 9 {
+			return 0, invalid
+		}
+		if nanos, err = strconv.ParseInt(fracPart+strings.Repeat("0", 9-len(fracPart)), 10, 64); err != nil {
+			return 0, invalid
+		}
+	}
+	d := time.Duration(days)*day + time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second + time.Duration(nanos)
+	if negative {
+		d = -d
+	}
+	return IntervalDayToSecond(d), nil
+}
+
+// intervalResult is a value of INTERVAL_DAY_TO_SECOND column given to database/sql.
+//
+// It is the text as returned by Timestream so that database/sql scans it into strings as is,
+// and String returns the nanoseconds so that database/sql scans it into time.Duration and integers.
+type intervalResult string
+
+func (r intervalResult) String() string {
+	parsed, err := parseIntervalDayToSecond(string(r))
+	if err != nil {
+		return string(r)
+	}
+	return strconv.FormatInt(int64(parsed), 10)
+}
+
+// IntervalYearToMonth is a value of INTERVAL_YEAR_TO_MONTH.
+//
+// It is rendered as a literal such as `INTERVAL '1-6' YEAR TO MONTH` as a parameter,
+// INTERVAL_YEAR_TO_MONTH columns can be scanned into this type.
+type IntervalYearToMonth struct {
+	Years  int
+	Months int
+}
+
+var _ interface {
+	driver.Valuer
+	sql.Scanner
+	bareValue
+	fmt.Stringer
+} = new(IntervalYearToMonth)
+
+// TotalMonths returns the interval in months.
+func (i IntervalYearToMonth) TotalMonths() int {
+	return i.Years*12 + i.Months
+}
+
+func (i IntervalYearToMonth) Value() (driver.Value, error) {
+	total := i.TotalMonths()
+	if total < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNegativeInterval, i)
+	}
+	return fmt.Sprintf("INTERVAL '%d-%d' YEAR TO MONTH", total/12, total%12), nil
+}
+
+func (IntervalYearToMonth) IsBareValue() {}
+
+// String returns the interval in the form of Timestream results such as `1-6`.
+func (i IntervalYearToMonth) String() string {
+	total := i.TotalMonths()
+	sign := ""
+	if total < 0 {
+		sign = "-"
+		total = -total
+	}
+	return fmt.Sprintf("%s%d-%d", sign, total/12, total%12)
+}
+
+func (i *IntervalYearToMonth) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case IntervalYearToMonth:
+		*i = src
+		return nil
+	case string:
+		parsed, err := parseIntervalYearToMonth(src)
+		if err != nil {
+			return err
+		}
+		*i = parsed
+		return nil
+	default:
+		return fmt.Errorf("timestream: cannot convert %T into IntervalYearToMonth", src)
+	}
+}
+
+// parseIntervalYearToMonth parses the form of `Y-M`.
+func parseIntervalYearToMonth(s string) (IntervalYearToMonth, error) {
+	invalid := fmt.Errorf("timestream: invalid interval year to month: %q", s)
+	body := s
+	negative := strings.HasPrefix(body, "-")
+	if negative {
+		body = body[1:]
+	}
+	yearsPart, monthsPart, ok := strings.Cut(body, "-")
+	if !ok {
+		return IntervalYearToMonth{}, invalid
+	}
+	years, err := strconv.Atoi(yearsPart)
+	if err != nil {
+		return IntervalYearToMonth{}, invalid
+	}
+	months, err := strconv.Atoi(monthsPart)
+	if err != nil {
+		return IntervalYearToMonth{}, invalid
+	}
+	if negative {
+		years, months = -years, -months
+	}
+	return IntervalYearToMonth{Years: years, Months: months}, nil
+}
@@ -0,0 +1,222 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+func TestIntervalDayToSecond_Value(t *testing.T) {
+	cases := []struct {
+		name    string
+		arg     IntervalDayToSecond
+		want    string
+		wantErr error
+	}{
+		{"zero", 0, "0s", nil},
+		{"days", IntervalDayToSecond(7 * day), "7d", nil},
+		{"hours", IntervalDayToSecond(time.Hour), "1h", nil},
+		{"minutes", IntervalDayToSecond(90 * time.Minute), "90m", nil},
+		{"milliseconds", IntervalDayToSecond(1500 * time.Millisecond), "1500ms", nil},
+		{"nanoseconds", IntervalDayToSecond(1001), "1001ns", nil},
+		{"negative", IntervalDayToSecond(-time.Second), "", ErrNegativeInterval},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.arg.Value()
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("expected error %v but got %v", c.wantErr, err)
+			}
+			if c.wantErr == nil && got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestIntervalDayToSecond_String(t *testing.T) {
+	cases := []struct {
+		arg  time.Duration
+		want string
+	}{
+		{0, "0 00:00:00.000000000"},
+		{time.Hour, "0 01:00:00.000000000"},
+		{90*day + 2*time.Hour + 3*time.Minute + 4*time.Second + 5, "90 02:03:04.000000005"},
+		{-time.Hour, "-0 01:00:00.000000000"},
+	}
+	for _, c := range cases {
+		t.Run(c.want, func(t *testing.T) {
+			if got := IntervalDayToSecond(c.arg).String(); got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+			parsed, err := parseIntervalDayToSecond(c.want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if time.Duration(parsed) != c.arg {
+				t.Errorf("parse: expected=%v got=%v", c.arg, time.Duration(parsed))
+			}
+		})
+	}
+}
+
+func Test_parseIntervalDayToSecond_Invalid(t *testing.T) {
+	for _, s := range []string{"", "1h", "0 01:00", "x 01:00:00.000", "0 01:00:00.0000000001"} {
+		if _, err := parseIntervalDayToSecond(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestIntervalYearToMonth(t *testing.T) {
+	cases := []struct {
+		arg       IntervalYearToMonth
+		wantValue string
+		wantStr   string
+		wantErr   error
+	}{
+		{IntervalYearToMonth{Years: 1, Months: 6}, "INTERVAL '1-6' YEAR TO MONTH", "1-6", nil},
+		{IntervalYearToMonth{Months: 18}, "INTERVAL '1-6' YEAR TO MONTH", "1-6", nil},
+		{IntervalYearToMonth{}, "INTERVAL '0-0' YEAR TO MONTH", "0-0", nil},
+		{IntervalYearToMonth{Years: -1, Months: -2}, "", "-1-2", ErrNegativeInterval},
+	}
+	for _, c := range cases {
+		t.Run(c.wantStr, func(t *testing.T) {
+			got, err := c.arg.Value()
+			if !errors.Is(err, c.wantErr) {
+				t.Fatalf("expected error %v but got %v", c.wantErr, err)
+			}
+			if c.wantErr == nil && got != c.wantValue {
+				t.Errorf("Value(): expected=%q got=%q", c.wantValue, got)
+			}
+			if s := c.arg.String(); s != c.wantStr {
+				t.Errorf("String(): expected=%q got=%q", c.wantStr, s)
+			}
+			var parsed IntervalYearToMonth
+			if err := parsed.Scan(c.wantStr); err != nil {
+				t.Fatal(err)
+			}
+			if parsed.TotalMonths() != c.arg.TotalMonths() {
+				t.Errorf("Scan(): expected=%v got=%v", c.arg, parsed)
+			}
+		})
+	}
+}
+
+func TestConn_QueryContext_IntervalParameter(t *testing.T) {
+	db, qr := newRecordingTestDB(t, Config{})
+	rows, err := db.QueryContext(context.Background(), `SELECT * FROM t WHERE time > ago(?) AND time < now() - ?`, IntervalDayToSecond(15*time.Minute), IntervalYearToMonth{Years: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if expected := `SELECT * FROM t WHERE time > ago(15m) AND time < now() - INTERVAL '1-0' YEAR TO MONTH`; qr.last() != expected {
+		t.Errorf("expected=%q got=%q", expected, qr.last())
+	}
+}
+
+func TestRows_Scan_Interval(t *testing.T) {
+	db, cleanup := prepareTestDB()
+	defer cleanup()
+	rows, err := db.QueryContext(context.Background(), "SELECT dur1, dur2 FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("expected a row")
+	}
+	var (
+		skip     interface{}
+		dayToSec IntervalDayToSecond
+		yearToMo IntervalYearToMonth
+	)
+	if err := rows.Scan(&skip, &skip, &skip, &skip, &skip, &dayToSec, &yearToMo, &skip, &skip, &skip, &skip, &skip); err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(dayToSec) != time.Hour {
+		t.Errorf("expected=%v got=%v", time.Hour, time.Duration(dayToSec))
+	}
+	if expected := (IntervalYearToMonth{Years: 1, Months: 6}); yearToMo != expected {
+		t.Errorf("expected=%v got=%v", expected, yearToMo)
+	}
+}
+
+func TestRows_Scan_IntervalInto(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&timestreamquery.QueryOutput{
+			ColumnInfo: []*timestreamquery.ColumnInfo{
+				scalarColumn("lag", timestreamquery.ScalarTypeIntervalDayToSecond),
+				arrayColumn("lags", timestreamquery.ScalarTypeIntervalDayToSecond),
+			},
+			Rows: []*timestreamquery.Row{{Data: []*timestreamquery.Datum{
+				scalarDatum("1 00:00:01.500000000"),
+				arrayValue("0 01:00:00.000000000", "0 00:00:00.000000001"),
+			}}},
+		})
+	}))
+	defer srv.Close()
+	tsq := timestreamquery.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+	db := sql.OpenDB(&connector{tsq: tsq})
+	queryRow := func(t *testing.T, dest ...interface{}) {
+		t.Helper()
+		if err := db.QueryRowContext(context.Background(), `SELECT lag, lags`).Scan(dest...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lag := 24*time.Hour + 1500*time.Millisecond
+
+	t.Run("duration", func(t *testing.T) {
+		var (
+			d    time.Duration
+			lags ArrayOf[time.Duration]
+		)
+		queryRow(t, &d, &lags)
+		if d != lag {
+			t.Errorf("expected=%s got=%s", lag, d)
+		}
+		if expected := (ArrayOf[time.Duration]{time.Hour, time.Nanosecond}); !reflect.DeepEqual(lags, expected) {
+			t.Errorf("expected=%v got=%v", expected, lags)
+		}
+	})
+	t.Run("string", func(t *testing.T) {
+		var (
+			s    sql.NullString
+			lags ArrayOf[string]
+		)
+		queryRow(t, &s, &lags)
+		if s.String != "1 00:00:01.500000000" {
+			t.Errorf("expected the text but got %q", s.String)
+		}
+		if expected := (ArrayOf[string]{"0 01:00:00.000000000", "0 00:00:00.000000001"}); !reflect.DeepEqual(lags, expected) {
+			t.Errorf("expected=%v got=%v", expected, lags)
+		}
+	})
+	t.Run("interval", func(t *testing.T) {
+		var (
+			d    IntervalDayToSecond
+			lags ArrayOf[IntervalDayToSecond]
+		)
+		queryRow(t, &d, &lags)
+		if time.Duration(d) != lag || len(lags) != 2 || time.Duration(lags[0]) != time.Hour {
+			t.Errorf("unexpected: %s %v", time.Duration(d), lags)
+		}
+	})
+}
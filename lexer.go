@@ -0,0 +1,214 @@
+package timestreamdriver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenWhitespace tokenKind = iota
+	tokenComment
+	tokenIdent
+	tokenQuotedIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+	tokenPositionalParam
+	tokenNamedParam
+	tokenOrdinalParam
+	tokenMacro
+)
+
+// token is a lexical unit of Timestream SQL.
+//
+// Concatenating text of all tokens reproduces the original query.
+type token struct {
+	kind tokenKind
+	text string
+	// pos is the byte offset of the token in the query
+	pos int
+	// name is the parameter or macro name without delimiters; only set for tokenNamedParam and tokenMacro
+	name string
+	// ordinal is the 1-based position of the parameter; only set for tokenOrdinalParam
+	ordinal int
+}
+
+// placeholder returns the placeholder style of the parameter token.
+func (t token) placeholder() Placeholder {
+	switch {
+	case t.kind == tokenOrdinalParam:
+		return PlaceholderDollar
+	case t.kind == tokenNamedParam && t.text[0] == '@':
+		return PlaceholderAt
+	case t.kind == tokenNamedParam && t.text[0] == ':':
+		return PlaceholderColon
+	default:
+		return PlaceholderQuestion
+	}
+}
+
+// lex splits the query into tokens.
+//
+// Placeholders of all styles (`?`, `$name$`, `$1`, `@name` and `:name`) are recognized only in code positions,
+// so that question marks and dollar signs in string literals, quoted identifiers and comments are kept as-is.
+// `$__database$` and `$__table$` are macros rather than named placeholders; other names starting with `__` are named placeholders.
+func lex(query string) ([]token, error) {
+	l := &lexer{src: query}
+	for l.pos < len(l.src) {
+		if err := l.next(); err != nil {
+			return nil, err
+		}
+	}
+	return l.tokens, nil
+}
+
+type lexer struct {
+	src    string
+	pos    int
+	tokens []token
+}
+
+func (l *lexer) emit(kind tokenKind, end int) {
+	l.tokens = append(l.tokens, token{kind: kind, text: l.src[l.pos:end], pos: l.pos})
+	l.pos = end
+}
+
+func (l *lexer) next() error {
+	rest := l.src[l.pos:]
+	r, size := utf8.DecodeRuneInString(rest)
+	switch {
+	case unicode.IsSpace(r):
+		end := strings.IndexFunc(rest, func(r rune) bool { return !unicode.IsSpace(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		l.emit(tokenWhitespace, l.pos+end)
+	case strings.HasPrefix(rest, "--"):
+		end := strings.IndexByte(rest, '\n')
+		if end < 0 {
+			end = len(rest)
+		}
+		l.emit(tokenComment, l.pos+end)
+	case strings.HasPrefix(rest, "/*"):
+		end := strings.Index(rest[2:], "*/")
+		if end < 0 {
+			return fmt.Errorf("unterminated comment at %d", l.pos)
+		}
+		l.emit(tokenComment, l.pos+2+end+2)
+	case r == '\'':
+		end, ok := scanQuoted(rest, '\'')
+		if !ok {
+			return fmt.Errorf("unterminated string literal at %d", l.pos)
+		}
+		l.emit(tokenString, l.pos+end)
+	case r == '"':
+		end, ok := scanQuoted(rest, '"')
+		if !ok {
+			return fmt.Errorf("unterminated quoted identifier at %d", l.pos)
+		}
+		l.emit(tokenQuotedIdent, l.pos+end)
+	case r == placeholder:
+		l.emit(tokenPositionalParam, l.pos+size)
+	case r == '$':
+		end := strings.IndexFunc(rest[1:], func(r rune) bool { return !isIdentRune(r) })
+		if end > 0 && rest[1+end] == '$' {
+			name := rest[1 : 1+end]
+			kind := tokenNamedParam
+			if isMacro(name) {
+				kind = tokenMacro
+			}
+			l.emit(kind, l.pos+1+end+1)
+			l.tokens[len(l.tokens)-1].name = name
+			return nil
+		}
+		digits := strings.IndexFunc(rest[1:], func(r rune) bool { return !isDigit(r) })
+		if digits < 0 {
+			digits = len(rest) - 1
+		}
+		// `$` not followed by digits is not a placeholder
+		if digits > 0 {
+			ordinal, err := strconv.Atoi(rest[1 : 1+digits])
+			if err != nil || ordinal < 1 {
+				return fmt.Errorf("invalid placeholder %s at %d", rest[:1+digits], l.pos)
+			}
+			l.emit(tokenOrdinalParam, l.pos+1+digits)
+			l.tokens[len(l.tokens)-1].ordinal = ordinal
+			return nil
+		}
+		l.emit(tokenPunct, l.pos+size)
+	case strings.HasPrefix(rest, "::"):
+		l.emit(tokenPunct, l.pos+2)
+	case (r == '@' || r == ':') && isIdentStart(firstRune(rest[1:])):
+		end := strings.IndexFunc(rest[1:], func(r rune) bool { return !isIdentRune(r) })
+		if end < 0 {
+			end = len(rest) - 1
+		}
+		l.emit(tokenNamedParam, l.pos+1+end)
+		l.tokens[len(l.tokens)-1].name = rest[1 : 1+end]
+	case isIdentStart(r):
+		end := strings.IndexFunc(rest, func(r rune) bool { return !isIdentRune(r) })
+		if end < 0 {
+			end = len(rest)
+		}
+		l.emit(tokenIdent, l.pos+end)
+	case isDigit(r) || (r == '.' && len(rest) > 1 && isDigit(rune(rest[1]))):
+		l.emit(tokenNumber, l.pos+scanNumber(rest))
+	default:
+		l.emit(tokenPunct, l.pos+size)
+	}
+	return nil
+}
+
+// scanQuoted returns the end offset of the quoted text that starts with `quote`.
+// The quote character is escaped by doubling it.
+func scanQuoted(s string, quote byte) (int, bool) {
+	for i := 1; i < len(s); i++ {
+		if s[i] != quote {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1, true
+	}
+	return 0, false
+}
+
+// scanNumber returns the end offset of the numeric literal.
+// Trailing letters are included to keep interval literals such as `1h` or `15ms` as one token.
+func scanNumber(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isDigit(rune(c)) || c == '.' || c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z'):
+			continue
+		case (c == '+' || c == '-') && (s[i-1] == 'e' || s[i-1] == 'E') && i+1 < len(s) && isDigit(rune(s[i+1])):
+			continue
+		}
+		return i
+	}
+	return len(s)
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentRune(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,65 @@
+package timestreamdriver
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_lex(t *testing.T) {
+	type kt struct {
+		kind tokenKind
+		text string
+	}
+	cases := []struct {
+		name    string
+		query   string
+		want    []kt
+		wantErr bool
+	}{
+		{"select", "SELECT 1", []kt{{tokenIdent, "SELECT"}, {tokenWhitespace, " "}, {tokenNumber, "1"}}, false},
+		{"positional", "a = ?", []kt{{tokenIdent, "a"}, {tokenWhitespace, " "}, {tokenPunct, "="}, {tokenWhitespace, " "}, {tokenPositionalParam, "?"}}, false},
+		{"named", "a=$name$", []kt{{tokenIdent, "a"}, {tokenPunct, "="}, {tokenNamedParam, "$name$"}}, false},
+		{"lone dollar", "$ $a", []kt{{tokenPunct, "$"}, {tokenWhitespace, " "}, {tokenPunct, "$"}, {tokenIdent, "a"}}, false},
+		{"string", "'a?''$b$'", []kt{{tokenString, "'a?''$b$'"}}, false},
+		{"quoted identifier", `"a?""b"."c"`, []kt{{tokenQuotedIdent, `"a?""b"`}, {tokenPunct, "."}, {tokenQuotedIdent, `"c"`}}, false},
+		{"line comment", "1 -- ?\n?", []kt{{tokenNumber, "1"}, {tokenWhitespace, " "}, {tokenComment, "-- ?"}, {tokenWhitespace, "\n"}, {tokenPositionalParam, "?"}}, false},
+		{"block comment", "/* ? $a$ */?", []kt{{tokenComment, "/* ? $a$ */"}, {tokenPositionalParam, "?"}}, false},
+		{"ordinal", "a=$1 OR b=$12", []kt{{tokenIdent, "a"}, {tokenPunct, "="}, {tokenOrdinalParam, "$1"}, {tokenWhitespace, " "}, {tokenIdent, "OR"}, {tokenWhitespace, " "}, {tokenIdent, "b"}, {tokenPunct, "="}, {tokenOrdinalParam, "$12"}}, false},
+		{"at", "a=@name", []kt{{tokenIdent, "a"}, {tokenPunct, "="}, {tokenNamedParam, "@name"}}, false},
+		{"colon", "a=:name::varchar", []kt{{tokenIdent, "a"}, {tokenPunct, "="}, {tokenNamedParam, ":name"}, {tokenPunct, "::"}, {tokenIdent, "varchar"}}, false},
+		{"zero ordinal", "$0", nil, true},
+		{"trailing dollar", "a=$", []kt{{tokenIdent, "a"}, {tokenPunct, "="}, {tokenPunct, "$"}}, false},
+		{"dollar before non-digit", "$x $(", []kt{{tokenPunct, "$"}, {tokenIdent, "x"}, {tokenWhitespace, " "}, {tokenPunct, "$"}, {tokenPunct, "("}}, false},
+		{"cast", "measure_value::double", []kt{{tokenIdent, "measure_value"}, {tokenPunct, "::"}, {tokenIdent, "double"}}, false},
+		{"numbers", "1.5 .5 1e-3 15m", []kt{{tokenNumber, "1.5"}, {tokenWhitespace, " "}, {tokenNumber, ".5"}, {tokenWhitespace, " "}, {tokenNumber, "1e-3"}, {tokenWhitespace, " "}, {tokenNumber, "15m"}}, false},
+		{"minus", "1-3", []kt{{tokenNumber, "1"}, {tokenPunct, "-"}, {tokenNumber, "3"}}, false},
+		{"unterminated string", "'a", nil, true},
+		{"unterminated quoted identifier", `"a`, nil, true},
+		{"unterminated comment", "/* a", nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tokens, err := lex(c.query)
+			if (err != nil) != c.wantErr {
+				t.Errorf("wantErr=%v err=%v", c.wantErr, err)
+				return
+			}
+			if err != nil {
+				return
+			}
+			got := make([]kt, len(tokens))
+			b := new(strings.Builder)
+			for i, tok := range tokens {
+				got[i] = kt{tok.kind, tok.text}
+				b.WriteString(tok.text)
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("mismatch\nexpected: %#v\n     got: %#v", c.want, got)
+			}
+			if b.String() != c.query {
+				t.Errorf("tokens do not reproduce the query: %q", b.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidStringLiteral is an error indicates the parameter cannot be encoded as Timestream string literal.
+// It may be returned by Rows.QueryContext().
+var ErrInvalidStringLiteral = errors.New("cannot encode string literal")
+
+// writeStringLiteral writes `s` as a single-quoted string literal.
+//
+// Single quotes are doubled so that no value can terminate the literal.
+// Tab, CR and LF are kept as-is but other control characters and invalid UTF-8 sequences are rejected.
+func writeStringLiteral(buf *bytes.Buffer, s string) error {
+	if err := validateStringLiteral(s); err != nil {
+		return err
+	}
+	buf.WriteByte('\'')
+	for _, r := range s {
+		if r == '\'' {
+			buf.WriteString("''")
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	buf.WriteByte('\'')
+	return nil
+}
+
+func validateStringLiteral(s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("%w: invalid UTF-8 sequence in %q", ErrInvalidStringLiteral, s)
+	}
+	for _, r := range s {
+		switch r {
+		case '\t', '\n', '\r':
+			continue
+		}
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: control character %U in %q", ErrInvalidStringLiteral, r, s)
+		}
+	}
+	return nil
+}
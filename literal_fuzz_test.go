@@ -0,0 +1,14 @@
+package timestreamdriver
+
+import "testing"
+
+func FuzzWriteStringLiteral(f *testing.F) {
+	for _, seed := range []string{"", "yuno", "O'Reilly", "'", "''", "' OR 1=1 --", "'; DROP TABLE t; --", "\\'", "a\x00b", "\xff"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if err := checkStringLiteralRoundTrip(s); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+func Test_writeStringLiteral(t *testing.T) {
+	cases := []struct {
+		name    string
+		arg     string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "''", false},
+		{"plain", "yuno", "'yuno'", false},
+		{"single quote", "O'Reilly", "'O''Reilly'", false},
+		{"only quotes", "''", "''''''", false},
+		{"injection", "' OR 1=1 --", "''' OR 1=1 --'", false},
+		{"double quote", `"yuno"`, `'"yuno"'`, false},
+		{"backslash", `\'`, `'\'''`, false},
+		{"multibyte", "ゆの", "'ゆの'", false},
+		{"whitespaces", "a\tb\r\nc", "'a\tb\r\nc'", false},
+		{"NUL", "a\x00b", "", true},
+		{"escape", "a\x1bb", "", true},
+		{"DEL", "a\x7fb", "", true},
+		{"C1 control", "a\u0085b", "", true},
+		{"invalid UTF-8", "a\xffb", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := writeStringLiteral(buf, c.arg)
+			if (err != nil) != c.wantErr {
+				t.Errorf("wantErr=%v err=%v", c.wantErr, err)
+				return
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidStringLiteral) {
+					t.Errorf("expected ErrInvalidStringLiteral but got %v", err)
+				}
+				return
+			}
+			if got := buf.String(); got != c.want {
+				t.Errorf("mismatch\nexpected: %q\n     got: %q", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_writeStringLiteral_roundTrip(t *testing.T) {
+	f := func(s string) bool {
+		return checkStringLiteralRoundTrip(s) == nil
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 10000}); err != nil {
+		t.Error(err)
+	}
+}
+
+// checkStringLiteralRoundTrip asserts that the literal encoded from `s` is exactly one string literal that decodes into `s`.
+func checkStringLiteralRoundTrip(s string) error {
+	buf := new(bytes.Buffer)
+	if err := writeStringLiteral(buf, s); err != nil {
+		if errors.Is(err, ErrInvalidStringLiteral) {
+			return nil
+		}
+		return err
+	}
+	tokens, err := lex(buf.String())
+	if err != nil {
+		return fmt.Errorf("%q: %w", s, err)
+	}
+	if len(tokens) != 1 || tokens[0].kind != tokenString {
+		return fmt.Errorf("%q: encoded into %d tokens", s, len(tokens))
+	}
+	decoded, err := unquoteStringLiteral(buf.String())
+	if err != nil {
+		return fmt.Errorf("%q: %w", s, err)
+	}
+	if decoded != s {
+		return fmt.Errorf("round trip mismatch\nexpected: %q\n     got: %q", s, decoded)
+	}
+	return nil
+}
+
+func unquoteStringLiteral(lit string) (string, error) {
+	if len(lit) < 2 || lit[0] != '\'' || lit[len(lit)-1] != '\'' {
+		return "", fmt.Errorf("not quoted: %q", lit)
+	}
+	body := lit[1 : len(lit)-1]
+	b := new(strings.Builder)
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\'' {
+			b.WriteByte(body[i])
+			continue
+		}
+		if i+1 < len(body) && body[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+			continue
+		}
+		return "", fmt.Errorf("literal terminated at %d: %q", i+1, lit)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,59 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// macroPrefix is the prefix of macro names that are expanded regardless of the placeholder style.
+const macroPrefix = "__"
+
+var (
+	macroDatabase = macroPrefix + "database"
+	macroTable    = macroPrefix + "table"
+)
+
+// isMacro reports whether the name of `$name$` is a macro; other names are named placeholders.
+func isMacro(name string) bool {
+	return name == macroDatabase || name == macroTable
+}
+
+// expandMacro returns tokens that the macro is replaced with.
+//
+// `$__database$` is expanded into the quoted default database (e.g. `"mydb"`)
+// and `$__table$` into the default table qualified with the default database (e.g. `"mydb"."mytable"`).
+// Both require the default database because Timestream rejects unqualified tables.
+// The names are validated in the same way as QualifiedIdent.
+func expandMacro(tok token, cfg Config) ([]token, error) {
+	buf := new(bytes.Buffer)
+	switch tok.name {
+	case macroDatabase:
+		if cfg.Database == "" {
+			return nil, fmt.Errorf("%s at %d requires the default database", tok.text, tok.pos)
+		}
+		v, err := QualifiedIdent{cfg.Database}.Value()
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(v.(string))
+	case macroTable:
+		if cfg.Database == "" || cfg.Table == "" {
+			return nil, fmt.Errorf("%s at %d requires the default database and table", tok.text, tok.pos)
+		}
+		v, err := QualifiedIdent{cfg.Database, cfg.Table}.Value()
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteString(v.(string))
+	default:
+		return nil, fmt.Errorf("unknown macro %s at %d", tok.text, tok.pos)
+	}
+	tokens, err := lex(buf.String())
+	if err != nil {
+		return nil, err
+	}
+	for i := range tokens {
+		tokens[i].pos = tok.pos
+	}
+	return tokens, nil
+}
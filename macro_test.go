@@ -0,0 +1,101 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func Test_interpolatesQuery_Macro(t *testing.T) {
+	dbAndTable := Config{Database: "mydb", Table: "metrics"}
+	cases := []struct {
+		name    string
+		cfg     Config
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"table", dbAndTable, `SELECT * FROM $__table$`, `SELECT * FROM "mydb"."metrics"`, false},
+		{"database", dbAndTable, `SELECT * FROM $__database$."other"`, `SELECT * FROM "mydb"."other"`, false},
+		{"table without database", Config{Table: "metrics"}, `SELECT * FROM $__table$`, ``, true},
+		{"invalid database", Config{Database: `my"db`, Table: "metrics"}, `SELECT * FROM $__table$`, ``, true},
+		{"invalid table", Config{Database: "mydb", Table: "my table"}, `SELECT * FROM $__table$`, ``, true},
+		{"other placeholder style", Config{Database: "mydb", Table: "metrics", Placeholder: PlaceholderColon}, `SELECT * FROM $__table$`, `SELECT * FROM "mydb"."metrics"`, false},
+		{"in literals", Config{}, `SELECT '$__table$' -- $__database$`, `SELECT '$__table$' -- $__database$`, false},
+		{"no database", Config{Table: "metrics"}, `SELECT * FROM $__database$."metrics"`, ``, true},
+		{"no table", Config{Database: "mydb"}, `SELECT * FROM $__table$`, ``, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := interpolatesQuery(c.query, c.cfg, nil)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("wantErr=%v err=%v", c.wantErr, err)
+			}
+			if got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_interpolatesQuery_MacroLikeNamedParameter(t *testing.T) {
+	got, err := interpolatesQuery(`SELECT * FROM $__table$ WHERE host = $__host$`, Config{Database: "mydb", Table: "metrics"},
+		[]driver.NamedValue{{Name: "__host", Value: "host-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `SELECT * FROM "mydb"."metrics" WHERE host = 'host-1'`; got != expected {
+		t.Errorf("expected=%q got=%q", expected, got)
+	}
+}
+
+func TestStatement_NumInput_Macro(t *testing.T) {
+	st, err := (&conn{cfg: Config{Database: "mydb", Table: "metrics"}}).Prepare(`SELECT * FROM $__table$ WHERE host = ?`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.NumInput(); got != 1 {
+		t.Errorf("NumInput(): expected=1 got=%d", got)
+	}
+}
+
+func TestConn_ExecContext_Macro(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	c := &conn{tsw: newWriteTestClient(srv), cfg: Config{Database: "mydb", Table: "metrics"}}
+	_, err := c.ExecContext(context.Background(), `INSERT INTO $__table$ (time, measure_name, measure_value::bigint) VALUES (?, 'count', ?)`,
+		[]driver.NamedValue{{Ordinal: 1, Value: time.Unix(1, 0)}, {Ordinal: 2, Value: int64(3)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.inputs) != 1 {
+		t.Fatalf("expected 1 WriteRecords call but got %d", len(ws.inputs))
+	}
+	if input := ws.inputs[0]; aws.StringValue(input.DatabaseName) != "mydb" || aws.StringValue(input.TableName) != "metrics" {
+		t.Errorf("unexpected table: %s.%s", aws.StringValue(input.DatabaseName), aws.StringValue(input.TableName))
+	}
+}
+
+func TestNewWriter_DefaultTable(t *testing.T) {
+	cfg := &Config{Region: "us-east-1", Database: "mydb", Table: "metrics"}
+	w, err := NewWriter(cfg, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.database != "mydb" || w.table != "metrics" {
+		t.Errorf("unexpected table: %s.%s", w.database, w.table)
+	}
+	if w, err = NewWriter(cfg, "otherdb", "other"); err != nil || w.database != "otherdb" || w.table != "other" {
+		t.Errorf("unexpected writer: %v, %v", w, err)
+	}
+	if _, err := NewWriter(&Config{Region: "us-east-1"}, "", ""); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
@@ -0,0 +1,144 @@
+package timestreamdriver
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// numericLiteral is a validated numeric literal rendered as is.
+type numericLiteral string
+
+var _ interface {
+	driver.Valuer
+	bareValue
+} = numericLiteral("")
+
+func (n numericLiteral) Value() (driver.Value, error) {
+	return string(n), nil
+}
+
+func (numericLiteral) IsBareValue() {}
+
+// convertParam converts a parameter into a value that formatParam renders.
+//
+// The conversion is:
+//
+//	nil, nil pointers                  NULL
+//	BareStringValue, ArrayOf, SliceValue, IntervalDayToSecond, IntervalYearToMonth, TimeValue, TimeOfDay, Ident, QualifiedIdent
+//	                                   kept as is and rendered by themselves
+//	time.Duration                      IntervalDayToSecond (e.g. `15m`)
+//	json.Number                        numeric literal as is; numbers not in the JSON grammar are an error
+//	driver.Valuer                      the result of Value(), converted again
+//	pointers                           the value pointed
+//	signed integers                    int64
+//	unsigned integers                  int64, or exact decimal literal if greater than math.MaxInt64
+//	floats                             float64
+//	bool, string, []byte, time.Time    as is; strings are quoted and times are rendered in UTC
+//	slices and arrays                  kept as is and expanded into lists
+//
+// Named types are converted by their underlying kinds. Other types are an error.
+func convertParam(v interface{}) (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, nil
+	}
+	switch v := v.(type) {
+	case bareValue:
+		return v, nil
+	case time.Duration:
+		return IntervalDayToSecond(v), nil
+	case json.Number:
+		if !isDecimalNumber(string(v)) {
+			return nil, fmt.Errorf("invalid number: %q", string(v))
+		}
+		return numericLiteral(v), nil
+	case time.Time:
+		return v, nil
+	}
+	if _, ok := v.(driver.Valuer); !ok && rv.Kind() == reflect.Ptr {
+		return convertParam(rv.Elem().Interface())
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := val.(driver.Valuer); ok {
+			return nil, fmt.Errorf("%T.Value() returns driver.Valuer", v)
+		}
+		return convertParam(val)
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := rv.Uint()
+		if u > math.MaxInt64 {
+			return numericLiteral(strconv.FormatUint(u, 10)), nil
+		}
+		return int64(u), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			if rv.Kind() == reflect.Slice {
+				return rv.Bytes(), nil
+			}
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return b, nil
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unsupported parameter type: %T", v)
+}
+
+// isDecimalNumber reports whether s is a number in the JSON grammar such as `-1.5e3`.
+// Forms that strconv.ParseFloat also accepts, such as `Inf`, `NaN` and hex floats, are not numeric literals of Timestream.
+func isDecimalNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		start := i
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	switch n := digits(); {
+	case n == 0:
+		return false
+	case n > 1 && s[i-n] == '0':
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
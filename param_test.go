@@ -0,0 +1,165 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+type hostName string
+
+type celsius float64
+
+type nullableHost struct {
+	name  string
+	valid bool
+}
+
+func (h nullableHost) Value() (driver.Value, error) {
+	if !h.valid {
+		return nil, nil
+	}
+	return hostName(h.name), nil
+}
+
+type failingValuer struct{}
+
+func (failingValuer) Value() (driver.Value, error) {
+	return nil, errors.New("oops")
+}
+
+func Test_convertParam(t *testing.T) {
+	str := "a"
+	var nilStr *string
+	var nilInterval *IntervalDayToSecond
+	cases := []struct {
+		name    string
+		arg     interface{}
+		want    string
+		wantErr bool
+	}{
+		{"nil", nil, `NULL`, false},
+		{"nil pointer", nilStr, `NULL`, false},
+		{"nil pointer to bare value", nilInterval, `NULL`, false},
+		{"pointer", &str, `'a'`, false},
+		{"int8", int8(-1), `-1`, false},
+		{"uint32", uint32(math.MaxUint32), `4294967295`, false},
+		{"uint64 max", uint64(math.MaxUint64), `18446744073709551615`, false},
+		{"uint64 small", uint64(1), `1`, false},
+		{"float32", float32(0.5), `0.5`, false},
+		{"duration", 90 * time.Second, `90s`, false},
+		{"negative duration", -time.Second, ``, true},
+		{"json.Number integer", json.Number("12345678901234567890"), `12345678901234567890`, false},
+		{"json.Number float", json.Number("-1.5e3"), `-1.5e3`, false},
+		{"invalid json.Number", json.Number("1; DROP"), ``, true},
+		{"json.Number zero", json.Number("0.0"), `0.0`, false},
+		{"json.Number Inf", json.Number("Inf"), ``, true},
+		{"json.Number negative Infinity", json.Number("-Infinity"), ``, true},
+		{"json.Number NaN", json.Number("NaN"), ``, true},
+		{"json.Number hex float", json.Number("0x1p4"), ``, true},
+		{"json.Number underscores", json.Number("1_000"), ``, true},
+		{"json.Number leading zero", json.Number("01"), ``, true},
+		{"json.Number leading plus", json.Number("+1"), ``, true},
+		{"json.Number no fraction digits", json.Number("1."), ``, true},
+		{"json.Number no exponent digits", json.Number("1e"), ``, true},
+		{"json.Number empty", json.Number(""), ``, true},
+		{"named string", hostName("O'Reilly"), `'O''Reilly'`, false},
+		{"named float", celsius(36.5), `36.5`, false},
+		{"valuer returns named type", nullableHost{name: "host-1", valid: true}, `'host-1'`, false},
+		{"valuer returns nil", nullableHost{}, `NULL`, false},
+		{"valuer fails", failingValuer{}, ``, true},
+		{"bare value", IntervalDayToSecond(time.Hour), `1h`, false},
+		{"time", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), `'2020-01-02 03:04:05'`, false},
+		{"unsupported", struct{}{}, ``, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := convertParam(c.arg)
+			if err == nil {
+				buf := new(bytes.Buffer)
+				err = formatParam(buf, v)
+				if err == nil && buf.String() != c.want {
+					t.Errorf("expected=%q got=%q", c.want, buf.String())
+				}
+			}
+			if c.wantErr != (err != nil) {
+				t.Errorf("wantErr=%v but got error: %v", c.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestConn_QueryContext_ConvertParameters(t *testing.T) {
+	db, qr := newRecordingTestDB(t, Config{})
+	ctx := context.Background()
+	var nilStr *string
+	for _, prepare := range []bool{false, true} {
+		query := `SELECT * FROM t WHERE host = ? AND id = ? AND time > ago(?) AND v = ? AND az = ?`
+		args := []interface{}{nilStr, uint64(math.MaxUint64), 15 * time.Minute, json.Number("0.25"), []hostName{"a", "b"}}
+		var rows *sql.Rows
+		var err error
+		if prepare {
+			stmt, perr := db.PrepareContext(ctx, query)
+			if perr != nil {
+				t.Fatal(perr)
+			}
+			defer stmt.Close()
+			rows, err = stmt.QueryContext(ctx, args...)
+		} else {
+			rows, err = db.QueryContext(ctx, query, args...)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+		if expected := `SELECT * FROM t WHERE host = NULL AND id = 18446744073709551615 AND time > ago(15m) AND v = 0.25 AND az = 'a', 'b'`; qr.last() != expected {
+			t.Errorf("prepare=%v: expected=%q got=%q", prepare, expected, qr.last())
+		}
+	}
+}
+
+func TestConn_ExecContext_ConvertParameters(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	db := newWriteTestDB(srv)
+
+	_, err := db.ExecContext(context.Background(),
+		`INSERT INTO "db1"."table1" (time, host, measure_name, measure_value::bigint) VALUES (?, ?, 'latency', ?), (?, ?, 'id', ?)`,
+		time.Unix(1, 0), Ident("host-1"), 15*time.Minute,
+		time.Unix(2, 0), hostName("host-2"), uint64(math.MaxUint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.inputs) != 1 {
+		t.Fatalf("expected 1 WriteRecords call but got %d", len(ws.inputs))
+	}
+	records := ws.inputs[0].Records
+	if got := aws.StringValue(records[0].MeasureValue); got != "900000000000" {
+		t.Errorf("Duration must be written as nanoseconds but got %s", got)
+	}
+	if got := aws.StringValue(records[1].MeasureValue); got != "18446744073709551615" {
+		t.Errorf("uint64 must be written exactly but got %s", got)
+	}
+	if got := aws.StringValue(records[0].Dimensions[0].Value); got != "host-1" {
+		t.Errorf("Ident must be written as the name but got %s", got)
+	}
+
+	_, err = db.ExecContext(context.Background(),
+		`INSERT INTO "db1"."table1" (time, host, measure_name, measure_value::bigint) VALUES (?, 'host-1', 'ids', ?)`,
+		time.Unix(1, 0), AsArray([]int{1, 2}))
+	if err == nil {
+		t.Error("expected error for array value but got nil")
+	}
+}
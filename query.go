@@ -0,0 +1,133 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+)
+
+// queryTemplate is a query that placeholders are located.
+type queryTemplate struct {
+	tokens []token
+	// numPositional is the number of positional placeholders (`?`)
+	numPositional int
+	// maxOrdinal is the largest position of ordinal placeholders (`$1`)
+	maxOrdinal int
+	// names are distinct names of named placeholders (`$name$`, `@name` or `:name`)
+	names map[string]bool
+}
+
+// parseQuery locates placeholders of the configured style in the query and expands macros with the default database and table.
+// Placeholders of other styles are an error.
+func parseQuery(query string, cfg Config) (*queryTemplate, error) {
+	placeholder := cfg.Placeholder
+	if placeholder == "" {
+		placeholder = PlaceholderQuestion
+	}
+	lexed, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]token, 0, len(lexed))
+	for _, tok := range lexed {
+		if tok.kind != tokenMacro {
+			tokens = append(tokens, tok)
+			continue
+		}
+		expanded, err := expandMacro(tok, cfg)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, expanded...)
+	}
+	tmpl := &queryTemplate{tokens: tokens, names: map[string]bool{}}
+	ordinals := map[int]bool{}
+	for _, tok := range tokens {
+		switch tok.kind {
+		case tokenPositionalParam, tokenNamedParam, tokenOrdinalParam:
+			if style := tok.placeholder(); style != placeholder {
+				return nil, fmt.Errorf("%w: %s placeholder %s at %d in the query of %s placeholders", ErrMixedPlaceholders, style, tok.text, tok.pos, placeholder)
+			}
+		}
+		switch tok.kind {
+		case tokenPositionalParam:
+			tmpl.numPositional++
+		case tokenNamedParam:
+			tmpl.names[tok.name] = true
+		case tokenOrdinalParam:
+			ordinals[tok.ordinal] = true
+			if tok.ordinal > tmpl.maxOrdinal {
+				tmpl.maxOrdinal = tok.ordinal
+			}
+		}
+	}
+	for i := 1; i <= tmpl.maxOrdinal; i++ {
+		if !ordinals[i] {
+			return nil, fmt.Errorf("placeholder $%d is missing while $%d is used", i, tmpl.maxOrdinal)
+		}
+	}
+	return tmpl, nil
+}
+
+// numInput returns the number of parameters the query requires.
+// A named or ordinal placeholder is counted once even if it appears multiple times.
+func (t *queryTemplate) numInput() int {
+	return t.numPositional + t.maxOrdinal + len(t.names)
+}
+
+// interpolate builds the query string that placeholders are replaced with formatted parameters.
+//
+// Positional placeholders (`?`) consume unnamed parameters in order, ordinal placeholders (`$1`) refer unnamed parameters by their positions
+// and named placeholders (`$name$`, `@name` or `:name`) refer named parameters.
+// Every placeholder must be given a parameter and every parameter must be referred by a placeholder.
+func (t *queryTemplate) interpolate(args []driver.NamedValue) (string, error) {
+	namedParams, err := formatNamedParams(args)
+	if err != nil {
+		return "", err
+	}
+	for name := range namedParams {
+		if !t.names[name] {
+			return "", fmt.Errorf("named parameter (%q) is not used in the query: %w", name, ErrTooManyParameters)
+		}
+	}
+	positionalArgs := make([]driver.NamedValue, 0, len(args))
+	for _, arg := range args {
+		if arg.Name == "" {
+			positionalArgs = append(positionalArgs, arg)
+		}
+	}
+	if numPlaceholders := t.numPositional + t.maxOrdinal; len(positionalArgs) > numPlaceholders {
+		return "", fmt.Errorf("query has %d placeholders but %d parameters passed: %w", numPlaceholders, len(positionalArgs), ErrTooManyParameters)
+	}
+
+	b := new(bytes.Buffer)
+	placeholderPos := 0
+	for _, tok := range t.tokens {
+		switch tok.kind {
+		case tokenPositionalParam:
+			if len(positionalArgs) < placeholderPos+1 {
+				return "", ErrTooFewParameters
+			}
+			if err := formatParam(b, positionalArgs[placeholderPos].Value); err != nil {
+				return "", err
+			}
+			placeholderPos++
+		case tokenOrdinalParam:
+			if len(positionalArgs) < tok.ordinal {
+				return "", fmt.Errorf("parameter %s not passed: %w", tok.text, ErrTooFewParameters)
+			}
+			if err := formatParam(b, positionalArgs[tok.ordinal-1].Value); err != nil {
+				return "", err
+			}
+		case tokenNamedParam:
+			formatted, ok := namedParams[tok.name]
+			if !ok {
+				return "", fmt.Errorf("named parameter (%q) not passed: %w", tok.name, ErrTooFewParameters)
+			}
+			b.WriteString(formatted)
+		default:
+			b.WriteString(tok.text)
+		}
+	}
+	return b.String(), nil
+}
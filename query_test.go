@@ -0,0 +1,122 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
+
+func Test_interpolatesQuery_Placeholder(t *testing.T) {
+	positional := []driver.NamedValue{{Ordinal: 1, Value: "a"}, {Ordinal: 2, Value: int64(1)}}
+	named := []driver.NamedValue{{Name: "host", Ordinal: 1, Value: "a"}, {Name: "n", Ordinal: 2, Value: int64(1)}}
+	cases := []struct {
+		name        string
+		placeholder Placeholder
+		query       string
+		args        []driver.NamedValue
+		want        string
+		wantErr     error
+	}{
+		{"default", "", `host = ? AND n = $n$`, []driver.NamedValue{{Ordinal: 1, Value: "a"}, {Name: "n", Ordinal: 2, Value: int64(1)}}, `host = 'a' AND n = 1`, nil},
+		{"dollar", PlaceholderDollar, `host = $1 AND n = $2`, positional, `host = 'a' AND n = 1`, nil},
+		{"dollar reused", PlaceholderDollar, `host = $1 OR n = $2 OR name = $1`, positional, `host = 'a' OR n = 1 OR name = 'a'`, nil},
+		{"dollar out of order", PlaceholderDollar, `n = $2 AND host = $1`, positional, `n = 1 AND host = 'a'`, nil},
+		{"dollar too few", PlaceholderDollar, `host = $1 AND n = $2`, positional[:1], ``, ErrTooFewParameters},
+		{"dollar too many", PlaceholderDollar, `host = $1`, positional, ``, ErrTooManyParameters},
+		{"dollar named", PlaceholderDollar, `host = $1`, named[:1], ``, ErrTooManyParameters},
+		{"at", PlaceholderAt, `host = @host AND n = @n OR name = @host`, named, `host = 'a' AND n = 1 OR name = 'a'`, nil},
+		{"colon", PlaceholderColon, `host = :host AND n = :n::bigint`, named, `host = 'a' AND n = 1::bigint`, nil},
+		{"colon positional", PlaceholderColon, `host = :host`, positional[:1], ``, ErrTooManyParameters},
+		{"literals are kept", PlaceholderAt, `host = @host AND s = '@n ?' -- :n`, named[:1], `host = 'a' AND s = '@n ?' -- :n`, nil},
+		{"question in dollar", PlaceholderDollar, `host = $1 AND n = ?`, positional, ``, ErrMixedPlaceholders},
+		{"dollar in default", "", `host = ? AND n = $2`, positional, ``, ErrMixedPlaceholders},
+		{"named in at", PlaceholderAt, `host = @host AND n = $n$`, named, ``, ErrMixedPlaceholders},
+		{"colon in at", PlaceholderAt, `host = @host AND n = :n`, named, ``, ErrMixedPlaceholders},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := interpolatesQuery(c.query, Config{Placeholder: c.placeholder}, c.args)
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("expected error %v but got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+		})
+	}
+}
+
+func Test_parseQuery_MissingOrdinal(t *testing.T) {
+	if _, err := parseQuery(`host = $1 AND n = $3`, Config{Placeholder: PlaceholderDollar}); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+func TestStatement_NumInput_Placeholder(t *testing.T) {
+	cases := []struct {
+		placeholder Placeholder
+		query       string
+		want        int
+	}{
+		{PlaceholderDollar, `host = $1 OR name = $1 AND n = $2`, 2},
+		{PlaceholderAt, `host = @host OR name = @host`, 1},
+		{PlaceholderColon, `host = :host AND n = :n`, 2},
+	}
+	for _, c := range cases {
+		t.Run(string(c.placeholder), func(t *testing.T) {
+			st, err := (&conn{cfg: Config{Placeholder: c.placeholder}}).Prepare(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := st.NumInput(); got != c.want {
+				t.Errorf("NumInput(): expected=%d got=%d", c.want, got)
+			}
+		})
+	}
+}
+
+func TestConn_QueryContext_Placeholder(t *testing.T) {
+	db, qr := newRecordingTestDB(t, Config{Placeholder: PlaceholderAt})
+	rows, err := db.QueryContext(context.Background(), `SELECT * FROM t WHERE host = @host OR name = @host`, sql.Named("host", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if expected := `SELECT * FROM t WHERE host = 'a' OR name = 'a'`; qr.last() != expected {
+		t.Errorf("expected=%q got=%q", expected, qr.last())
+	}
+}
+
+func TestConn_ExecContext_Placeholder(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	c := &conn{tsw: newWriteTestClient(srv), cfg: Config{Placeholder: PlaceholderDollar}}
+	_, err := c.ExecContext(context.Background(), `INSERT INTO "db1"."table1" (time, measure_name, measure_value::bigint) VALUES ($1, 'count', $2), ($1, 'total', $2)`,
+		[]driver.NamedValue{{Ordinal: 1, Value: time.Unix(1, 0)}, {Ordinal: 2, Value: int64(3)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.inputs) != 1 || len(ws.inputs[0].Records) != 2 {
+		t.Fatalf("unexpected inputs: %v", ws.inputs)
+	}
+	for _, rec := range ws.inputs[0].Records {
+		if aws.StringValue(rec.MeasureValue) != "3" || aws.StringValue(rec.Time) != "1000000000" {
+			t.Errorf("unexpected record: %s", rec)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package timestreamdriver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+)
+
+// RejectedRecord is a record that Timestream rejected.
+type RejectedRecord struct {
+	// Index is the position of the record in the records given to the write.
+	// For BufferedWriter it is the position in the batch, so refer Record instead.
+	Index int `json:"index"`
+	// Record is the rejected record.
+	Record *timestreamwrite.Record `json:"record"`
+	// Reason is the reason why the record was rejected.
+	Reason string `json:"reason"`
+	// ExistingVersion is the version of the record already stored if the rejection is due to a version conflict.
+	ExistingVersion *int64 `json:"existingVersion,omitempty"`
+}
+
+// RejectedRecordsError is an error returned when Timestream rejects some records.
+// Records other than rejected ones are ingested.
+type RejectedRecordsError struct {
+	Rejected []RejectedRecord
+	// Ingested is the number of records ingested.
+	Ingested int64
+	// Err is the error that stopped writing following batches after some records were rejected, if any.
+	// It is *UnwrittenRecordsError that holds the records not written.
+	Err error
+}
+
+func (e *RejectedRecordsError) Error() string {
+	reasons := make([]string, 0, len(e.Rejected))
+	for _, r := range e.Rejected {
+		reasons = append(reasons, fmt.Sprintf("#%d: %s", r.Index, r.Reason))
+	}
+	msg := fmt.Sprintf("%d records rejected: %s", len(e.Rejected), strings.Join(reasons, "; "))
+	if e.Err != nil {
+		msg += fmt.Sprintf("; then writing failed: %s", e.Err)
+	}
+	return msg
+}
+
+func (e *RejectedRecordsError) Unwrap() error {
+	return e.Err
+}
+
+// UnwrittenRecordsError is an error returned when writing is stopped by a failure such as throttling, network errors or cancellation.
+// Records before Unwritten are ingested or rejected, and Unwritten are not sent or failed to be written, so they can be retried.
+type UnwrittenRecordsError struct {
+	// Unwritten are records from the failed batch to the last in the given order.
+	Unwritten []*timestreamwrite.Record
+	// Ingested is the number of records ingested before the failure.
+	Ingested int64
+	// Err is the failure.
+	Err error
+}
+
+func (e *UnwrittenRecordsError) Error() string {
+	return fmt.Sprintf("%d records not written: %s", len(e.Unwritten), e.Err)
+}
+
+func (e *UnwrittenRecordsError) Unwrap() error {
+	return e.Err
+}
+
+// rejectedRecords maps RejectedRecordsException of the batch that starts at `offset` back into given records.
+func rejectedRecords(ex *timestreamwrite.RejectedRecordsException, records []*timestreamwrite.Record, offset int) []RejectedRecord {
+	rejected := make([]RejectedRecord, 0, len(ex.RejectedRecords))
+	for _, r := range ex.RejectedRecords {
+		index := offset + int(aws.Int64Value(r.RecordIndex))
+		rr := RejectedRecord{Index: index, Reason: aws.StringValue(r.Reason), ExistingVersion: r.ExistingVersion}
+		if index < len(records) {
+			rr.Record = records[index]
+		}
+		rejected = append(rejected, rr)
+	}
+	return rejected
+}
+
+// DeadLetterSink receives records that Timestream rejected so that they are not lost silently.
+type DeadLetterSink interface {
+	Put(ctx context.Context, rejected []RejectedRecord) error
+}
+
+// DeadLetterFunc is an adapter to use a function as DeadLetterSink.
+type DeadLetterFunc func(ctx context.Context, rejected []RejectedRecord) error
+
+var _ DeadLetterSink = DeadLetterFunc(nil)
+
+func (f DeadLetterFunc) Put(ctx context.Context, rejected []RejectedRecord) error {
+	return f(ctx, rejected)
+}
+
+// NDJSONDeadLetterSink writes rejected records into w as newline delimited JSON.
+// It is safe for concurrent use.
+type NDJSONDeadLetterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var _ DeadLetterSink = &NDJSONDeadLetterSink{}
+
+// NewNDJSONDeadLetterSink returns a new NDJSONDeadLetterSink that writes into w.
+func NewNDJSONDeadLetterSink(w io.Writer) *NDJSONDeadLetterSink {
+	return &NDJSONDeadLetterSink{w: w}
+}
+
+func (s *NDJSONDeadLetterSink) Put(ctx context.Context, rejected []RejectedRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	enc := json.NewEncoder(s.w)
+	for _, r := range rejected {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
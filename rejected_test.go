@@ -0,0 +1,165 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+func TestWriter_Write_Rejected(t *testing.T) {
+	ws := &writeServer{reject: "7"}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	w := newTestWriter(t, srv)
+	buf := new(bytes.Buffer)
+	w.DeadLetter = NewNDJSONDeadLetterSink(buf)
+
+	metrics := make([]cpuMetric, 150)
+	for i := range metrics {
+		metrics[i] = cpuMetric{Time: time.Unix(int64(i), 0), Host: "host-1", Usage: float64(i % 10)}
+	}
+	n, err := w.Write(context.Background(), metrics)
+	var rejectedErr *RejectedRecordsError
+	if !errors.As(err, &rejectedErr) {
+		t.Fatalf("expected RejectedRecordsError but got %v", err)
+	}
+	if n != 135 || rejectedErr.Ingested != 135 {
+		t.Errorf("expected 135 records ingested but got %d (error: %d)", n, rejectedErr.Ingested)
+	}
+	if len(rejectedErr.Rejected) != 15 {
+		t.Fatalf("expected 15 records rejected but got %d", len(rejectedErr.Rejected))
+	}
+	for i, r := range rejectedErr.Rejected {
+		if expected := i*10 + 7; r.Index != expected {
+			t.Errorf("#%d: Index: expected=%d got=%d", i, expected, r.Index)
+		}
+		// records are mapped to the input that still has common dimensions
+		if len(r.Record.Dimensions) != 1 || aws.StringValue(r.Record.MeasureValue) != "7" {
+			t.Errorf("#%d: unexpected record: %s", i, r.Record)
+		}
+		if r.Reason == "" || aws.Int64Value(r.ExistingVersion) != 1 {
+			t.Errorf("#%d: unexpected rejection: %#v", i, r)
+		}
+	}
+
+	dec := json.NewDecoder(buf)
+	lines := 0
+	for dec.More() {
+		var r RejectedRecord
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Index != rejectedErr.Rejected[lines].Index {
+			t.Errorf("dead letter #%d: Index: expected=%d got=%d", lines, rejectedErr.Rejected[lines].Index, r.Index)
+		}
+		lines++
+	}
+	if lines != 15 {
+		t.Errorf("expected 15 dead letters but got %d", lines)
+	}
+}
+
+func TestWriter_Write_DeadLetterFailed(t *testing.T) {
+	ws := &writeServer{reject: "0"}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	w := newTestWriter(t, srv)
+	dlErr := errors.New("sink unavailable")
+	w.DeadLetter = DeadLetterFunc(func(ctx context.Context, rejected []RejectedRecord) error {
+		return dlErr
+	})
+
+	_, err := w.Write(context.Background(), cpuMetric{Time: time.Unix(1, 0), Host: "host-1", Usage: 0})
+	var rejectedErr *RejectedRecordsError
+	if !errors.As(err, &rejectedErr) {
+		t.Errorf("expected RejectedRecordsError but got %v", err)
+	}
+}
+
+func TestConn_ExecContext_Rejected(t *testing.T) {
+	ws := &writeServer{reject: "2"}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	db := newWriteTestDB(srv)
+
+	_, err := db.ExecContext(context.Background(), `INSERT INTO db1.table1 (time, measure_name, measure_value::bigint) VALUES (?, 'count', 1), (?, 'count', 2)`, time.Unix(1, 0), time.Unix(2, 0))
+	var rejectedErr *RejectedRecordsError
+	if !errors.As(err, &rejectedErr) {
+		t.Fatalf("expected RejectedRecordsError but got %v", err)
+	}
+	if len(rejectedErr.Rejected) != 1 || rejectedErr.Rejected[0].Index != 1 {
+		t.Errorf("unexpected rejected records: %#v", rejectedErr.Rejected)
+	}
+}
+
+func TestWriter_Write_RejectedThenFailed(t *testing.T) {
+	ws := &writeServer{reject: "7", failFrom: 2}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	w := newTestWriter(t, srv)
+	var dead []RejectedRecord
+	w.DeadLetter = DeadLetterFunc(func(ctx context.Context, rejected []RejectedRecord) error {
+		dead = append(dead, rejected...)
+		return nil
+	})
+
+	metrics := make([]cpuMetric, 150)
+	for i := range metrics {
+		metrics[i] = cpuMetric{Time: time.Unix(int64(i), 0), Host: "host-1", Usage: float64(i % 10)}
+	}
+	n, err := w.Write(context.Background(), metrics)
+	var rejectedErr *RejectedRecordsError
+	if !errors.As(err, &rejectedErr) {
+		t.Fatalf("expected RejectedRecordsError but got %v", err)
+	}
+	if n != 90 || rejectedErr.Ingested != 90 {
+		t.Errorf("expected 90 records ingested but got %d (error: %d)", n, rejectedErr.Ingested)
+	}
+	if len(rejectedErr.Rejected) != 10 {
+		t.Errorf("expected 10 records rejected but got %d", len(rejectedErr.Rejected))
+	}
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) || awsErr.Code() != "InternalServerException" {
+		t.Errorf("expected the failure of the second batch to be wrapped but got %v", rejectedErr.Err)
+	}
+	if len(dead) != 10 {
+		t.Errorf("expected 10 records put into dead letter sink but got %d", len(dead))
+	}
+	var unwrittenErr *UnwrittenRecordsError
+	if !errors.As(err, &unwrittenErr) {
+		t.Fatalf("expected UnwrittenRecordsError to be wrapped but got %v", rejectedErr.Err)
+	}
+	if len(unwrittenErr.Unwritten) != 50 || aws.StringValue(unwrittenErr.Unwritten[0].Time) != "100000" {
+		t.Errorf("expected the records of the second batch but got %d records from %s", len(unwrittenErr.Unwritten), unwrittenErr.Unwritten[0])
+	}
+}
+
+func TestWriter_Write_Failed(t *testing.T) {
+	ws := &writeServer{failFrom: 2}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	w := newTestWriter(t, srv)
+
+	n, err := w.Write(context.Background(), cpuMetrics(250))
+	var unwrittenErr *UnwrittenRecordsError
+	if !errors.As(err, &unwrittenErr) {
+		t.Fatalf("expected UnwrittenRecordsError but got %v", err)
+	}
+	if n != 100 || unwrittenErr.Ingested != 100 {
+		t.Errorf("expected 100 records ingested but got %d (error: %d)", n, unwrittenErr.Ingested)
+	}
+	if len(unwrittenErr.Unwritten) != 150 || aws.StringValue(unwrittenErr.Unwritten[0].Time) != "100000" {
+		t.Errorf("expected the records from the second batch but got %d records from %s", len(unwrittenErr.Unwritten), unwrittenErr.Unwritten[0])
+	}
+	var awsErr awserr.Error
+	if !errors.As(err, &awsErr) || awsErr.Code() != "InternalServerException" {
+		t.Errorf("expected the failure to be wrapped but got %v", err)
+	}
+}
@@ -0,0 +1,308 @@
+package timestreamdriver
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+// Row is a value of ROW column that scannable by database/sql.
+//
+// Keys are field names of the row and values are converted in the same way as scalar columns.
+// Nested rows are Row, arrays are []interface{}, time series are TimeSeries and NULLs are nil.
+// A NULL ROW column is scanned as nil Row.
+type Row map[string]interface{}
+
+var _ sql.Scanner = &Row{}
+
+func (r *Row) Scan(src interface{}) error {
+	if src == nil {
+		*r = nil
+		return nil
+	}
+	cd, err := columnDatumOf(src)
+	if err != nil {
+		return err
+	}
+	if cd.columnInfo.Type.RowColumnInfo == nil {
+		return fmt.Errorf("timestream: cannot convert non-row column into Row")
+	}
+	v, err := datumValue(cd.datum, cd.columnInfo, cd.opts)
+	if err != nil {
+		return err
+	}
+	row, _ := v.(Row)
+	*r = row
+	return nil
+}
+
+// Decode stores the row into dest that must be a pointer to map[string]interface{} or to struct.
+//
+// Struct fields are matched with `name=` option of `ts` tag, or the field name case-insensitively.
+// Fields tagged with `ts:"-"` are ignored.
+func (r Row) Decode(dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("timestream: cannot decode row into non-pointer %T", dest)
+	}
+	return assignValue(rv.Elem(), r)
+}
+
+// RowInto returns a scanner that decodes ROW column into dest in the same way as Row.Decode.
+func RowInto(dest interface{}) sql.Scanner {
+	return &rowScanner{dest: dest}
+}
+
+type rowScanner struct {
+	dest interface{}
+}
+
+func (s *rowScanner) Scan(src interface{}) error {
+	if src == nil {
+		rv := reflect.ValueOf(s.dest)
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return fmt.Errorf("timestream: cannot decode row into non-pointer %T", s.dest)
+		}
+		rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+		return nil
+	}
+	var row Row
+	if err := row.Scan(src); err != nil {
+		return err
+	}
+	return row.Decode(s.dest)
+}
+
+// datumValue converts the datum into Go value recursively.
+func datumValue(datum *timestreamquery.Datum, columnInfo *timestreamquery.ColumnInfo, opts scanOptions) (interface{}, error) {
+	if datum == nil || (datum.NullValue != nil && *datum.NullValue) {
+		return nil, nil
+	}
+	switch typ := columnInfo.Type; {
+	case typ.RowColumnInfo != nil:
+		if datum.RowValue == nil {
+			return nil, nil
+		}
+		if len(datum.RowValue.Data) != len(typ.RowColumnInfo) {
+			return nil, fmt.Errorf("timestream: row has %d values for %d fields", len(datum.RowValue.Data), len(typ.RowColumnInfo))
+		}
+		row := make(Row, len(typ.RowColumnInfo))
+		for i, field := range typ.RowColumnInfo {
+			v, err := datumValue(datum.RowValue.Data[i], field, opts)
+			if err != nil {
+				return nil, err
+			}
+			row[rowFieldName(field, i)] = v
+		}
+		return row, nil
+	case typ.ArrayColumnInfo != nil:
+		xs := make([]interface{}, len(datum.ArrayValue))
+		for i, elem := range datum.ArrayValue {
+			v, err := datumValue(elem, typ.ArrayColumnInfo, opts)
+			if err != nil {
+				return nil, err
+			}
+			xs[i] = v
+		}
+		return xs, nil
+	case typ.TimeSeriesMeasureValueColumnInfo != nil:
+		return timeSeriesValue(datum, typ.TimeSeriesMeasureValueColumnInfo, opts)
+	case typ.ScalarType != nil:
+		return scanScalarColumn(datum, columnInfo, opts)
+	default:
+		return nil, fmt.Errorf("column (%s) not handled", rowFieldName(columnInfo, 0))
+	}
+}
+
+// rowFieldName returns the name of the field; anonymous fields are named after the position like `field0`.
+func rowFieldName(columnInfo *timestreamquery.ColumnInfo, i int) string {
+	if columnInfo.Name != nil && *columnInfo.Name != "" {
+		return *columnInfo.Name
+	}
+	return fmt.Sprintf("field%d", i)
+}
+
+// assignValue stores src into dst converting types as database/sql does.
+func assignValue(dst reflect.Value, src interface{}) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return assignValue(dst.Elem(), src)
+	}
+	sv := reflect.ValueOf(src)
+	if dst.Kind() == reflect.Interface && sv.Type().Implements(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	if row, ok := src.(Row); ok {
+		return decodeRow(dst, row)
+	}
+	if xs, ok := src.([]interface{}); ok && dst.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(dst.Type(), len(xs), len(xs))
+		for i, x := range xs {
+			if err := assignValue(slice.Index(i), x); err != nil {
+				return fmt.Errorf("#%d: %w", i, err)
+			}
+		}
+		dst.Set(slice)
+		return nil
+	}
+	if dst.CanAddr() {
+		if scanner, ok := dst.Addr().Interface().(sql.Scanner); ok {
+			return scanner.Scan(src)
+		}
+	}
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+	// TIME has no date, so it is put on the zero date (January 1, year 1 UTC)
+	if tod, ok := src.(TimeOfDay); ok && dst.Type() == timeType {
+		dst.Set(reflect.ValueOf(tod.On(time.Time{})))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch sv.Kind() {
+		case reflect.Int64:
+			if dst.OverflowInt(sv.Int()) {
+				return fmt.Errorf("timestream: %v overflows %s", src, dst.Type())
+			}
+			dst.SetInt(sv.Int())
+			return nil
+		case reflect.Uint64:
+			if sv.Uint() > math.MaxInt64 || dst.OverflowInt(int64(sv.Uint())) {
+				return fmt.Errorf("timestream: %v overflows %s", src, dst.Type())
+			}
+			dst.SetInt(int64(sv.Uint()))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch sv.Kind() {
+		case reflect.Int64:
+			if sv.Int() < 0 || dst.OverflowUint(uint64(sv.Int())) {
+				return fmt.Errorf("timestream: %v overflows %s", src, dst.Type())
+			}
+			dst.SetUint(uint64(sv.Int()))
+			return nil
+		case reflect.Uint64:
+			if dst.OverflowUint(sv.Uint()) {
+				return fmt.Errorf("timestream: %v overflows %s", src, dst.Type())
+			}
+			dst.SetUint(sv.Uint())
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch sv.Kind() {
+		case reflect.Int64:
+			f = float64(sv.Int())
+		case reflect.Uint64:
+			f = float64(sv.Uint())
+		case reflect.Float64:
+			f = sv.Float()
+		default:
+			return fmt.Errorf("timestream: cannot convert %T into %s", src, dst.Type())
+		}
+		if dst.OverflowFloat(f) {
+			return fmt.Errorf("timestream: %v overflows %s", src, dst.Type())
+		}
+		dst.SetFloat(f)
+		return nil
+	case reflect.String, reflect.Bool:
+		if sv.Kind() == dst.Kind() {
+			dst.Set(sv.Convert(dst.Type()))
+			return nil
+		}
+		// intervals and TIME are rendered as the text as returned by Timestream
+		switch src := src.(type) {
+		case IntervalDayToSecond, IntervalYearToMonth, TimeOfDay:
+			if dst.Kind() == reflect.String {
+				dst.SetString(src.(fmt.Stringer).String())
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("timestream: cannot convert %T into %s", src, dst.Type())
+}
+
+func decodeRow(dst reflect.Value, row Row) error {
+	switch dst.Kind() {
+	case reflect.Map:
+		if dst.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("timestream: cannot decode row into %s", dst.Type())
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), len(row))
+		for k, v := range row {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assignValue(elem, v); err != nil {
+				return fmt.Errorf("field (%s): %w", k, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(m)
+		return nil
+	case reflect.Struct:
+		typ := dst.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			sf := typ.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			name, ok := structFieldName(sf)
+			if !ok {
+				continue
+			}
+			v, found := lookupRowField(row, name)
+			if !found {
+				continue
+			}
+			if err := assignValue(dst.Field(i), v); err != nil {
+				return fmt.Errorf("field (%s): %w", name, err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("timestream: cannot decode row into %s", dst.Type())
+	}
+}
+
+// structFieldName returns the row field name of the struct field given by `name=` option of `ts` tag.
+func structFieldName(sf reflect.StructField) (string, bool) {
+	tag, ok := sf.Tag.Lookup(tagName)
+	if !ok {
+		return sf.Name, true
+	}
+	if tag == "-" {
+		return "", false
+	}
+	for _, elem := range strings.Split(tag, ",") {
+		if strings.HasPrefix(elem, "name=") {
+			return strings.TrimPrefix(elem, "name="), true
+		}
+	}
+	return sf.Name, true
+}
+
+// lookupRowField finds the field by name; the exact match is preferred to case-insensitive one.
+func lookupRowField(row Row, name string) (interface{}, bool) {
+	if v, ok := row[name]; ok {
+		return v, true
+	}
+	for k, v := range row {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
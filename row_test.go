@@ -0,0 +1,230 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+func rowColumn(name string, fields ...*timestreamquery.ColumnInfo) *timestreamquery.ColumnInfo {
+	return &timestreamquery.ColumnInfo{Name: aws.String(name), Type: &timestreamquery.Type{RowColumnInfo: fields}}
+}
+
+func rowDatum(data ...*timestreamquery.Datum) *timestreamquery.Datum {
+	return &timestreamquery.Datum{RowValue: &timestreamquery.Row{Data: data}}
+}
+
+func scalarDatum(v string) *timestreamquery.Datum {
+	return &timestreamquery.Datum{ScalarValue: aws.String(v)}
+}
+
+var nullDatum = &timestreamquery.Datum{NullValue: aws.Bool(true)}
+
+type hostLocation struct {
+	Region string
+	Zone   *string `ts:"name=az"`
+}
+
+type hostRow struct {
+	Host     string        `ts:"name=host"`
+	CPU      float32       `ts:"name=cpu"`
+	Cores    int           `ts:"name=cores"`
+	Time     time.Time     `ts:"name=time"`
+	Tags     []string      `ts:"name=tags"`
+	Location *hostLocation `ts:"name=location"`
+	Ignored  string        `ts:"-"`
+}
+
+func TestConn_QueryContext_Row(t *testing.T) {
+	columns := []*timestreamquery.ColumnInfo{
+		rowColumn("r",
+			scalarColumn("host", timestreamquery.ScalarTypeVarchar),
+			scalarColumn("cpu", timestreamquery.ScalarTypeDouble),
+			scalarColumn("cores", timestreamquery.ScalarTypeBigint),
+			scalarColumn("time", timestreamquery.ScalarTypeTimestamp),
+			arrayColumn("tags", timestreamquery.ScalarTypeVarchar),
+			rowColumn("location",
+				scalarColumn("region", timestreamquery.ScalarTypeVarchar),
+				scalarColumn("az", timestreamquery.ScalarTypeVarchar),
+			),
+			scalarColumn("ignored", timestreamquery.ScalarTypeVarchar),
+		),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&timestreamquery.QueryOutput{
+			ColumnInfo: columns,
+			Rows: []*timestreamquery.Row{
+				{Data: []*timestreamquery.Datum{rowDatum(
+					scalarDatum("host-1"), scalarDatum("0.5"), scalarDatum("8"), scalarDatum("2020-01-02 03:04:05.000000000"),
+					arrayValue("a", "b"), rowDatum(scalarDatum("ap-northeast-1"), nullDatum), scalarDatum("x"),
+				)}},
+				{Data: []*timestreamquery.Datum{rowDatum(
+					scalarDatum("host-2"), nullDatum, nullDatum, scalarDatum("2020-01-02 03:04:05.000000000"),
+					nullDatum, nullDatum, nullDatum,
+				)}},
+				{Data: []*timestreamquery.Datum{nullDatum}},
+			},
+		})
+	}))
+	defer srv.Close()
+	tsq := timestreamquery.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+	db := sql.OpenDB(&connector{tsq: tsq})
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	t.Run("struct", func(t *testing.T) {
+		rows, err := db.QueryContext(context.Background(), `SELECT r FROM t`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var got []*hostRow
+		for rows.Next() {
+			var r *hostRow
+			if err := rows.Scan(RowInto(&r)); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, r)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		expected := []*hostRow{
+			{Host: "host-1", CPU: 0.5, Cores: 8, Time: ts, Tags: []string{"a", "b"}, Location: &hostLocation{Region: "ap-northeast-1"}},
+			{Host: "host-2", Time: ts},
+			nil,
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected=%#v\n     got=%#v", expected, got)
+		}
+	})
+	t.Run("map", func(t *testing.T) {
+		rows, err := db.QueryContext(context.Background(), `SELECT r FROM t`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			t.Fatal(rows.Err())
+		}
+		var r Row
+		if err := rows.Scan(&r); err != nil {
+			t.Fatal(err)
+		}
+		expected := Row{
+			"host": "host-1", "cpu": 0.5, "cores": int64(8), "time": ts, "tags": []interface{}{"a", "b"},
+			"location": Row{"region": "ap-northeast-1", "az": nil}, "ignored": "x",
+		}
+		if !reflect.DeepEqual(r, expected) {
+			t.Errorf("expected=%#v\n     got=%#v", expected, r)
+		}
+		var m map[string]interface{}
+		if err := r.Decode(&m); err != nil {
+			t.Fatal(err)
+		}
+		if m["location"].(Row)["region"] != "ap-northeast-1" {
+			t.Errorf("unexpected map: %#v", m)
+		}
+	})
+	t.Run("null", func(t *testing.T) {
+		rows, err := db.QueryContext(context.Background(), `SELECT r FROM t`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var got []Row
+		for rows.Next() {
+			r := Row{"stale": true}
+			if err := rows.Scan(&r); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, r)
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 3 || got[2] != nil {
+			t.Errorf("expected the NULL row scanned as nil but got %#v", got)
+		}
+	})
+	t.Run("raw", func(t *testing.T) {
+		rows, err := db.QueryContext(context.Background(), `SELECT r FROM t`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		if !rows.Next() {
+			t.Fatal(rows.Err())
+		}
+		var (
+			b   []byte
+			s   string
+			raw sql.RawBytes
+			v   interface{}
+		)
+		// RawBytes is scanned last because it holds the row until Next
+		for _, dest := range []interface{}{&b, &s, &v, &raw} {
+			if err := rows.Scan(dest); err != nil {
+				t.Fatalf("Scan(%T): %v", dest, err)
+			}
+		}
+		if _, ok := v.([]byte); !ok {
+			t.Errorf("expected []byte scanned into interface{} but got %T", v)
+		}
+		if string(b) != s || string(raw) != s {
+			t.Errorf("expected the same JSON: []byte=%s string=%s RawBytes=%s", b, s, raw)
+		}
+		if !rows.Next() {
+			t.Fatal(rows.Err())
+		}
+		// the copied JSON of the previous row is decoded
+		var r Row
+		if err := r.Scan(b); err != nil {
+			t.Fatal(err)
+		}
+		if r["host"] != "host-1" || r["location"].(Row)["region"] != "ap-northeast-1" {
+			t.Errorf("unexpected row: %#v", r)
+		}
+	})
+}
+
+func TestRow_Decode(t *testing.T) {
+	cases := []struct {
+		name    string
+		row     Row
+		dest    interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"case-insensitive name", Row{"region": "us-east-1"}, &hostLocation{}, &hostLocation{Region: "us-east-1"}, false},
+		{"overflow", Row{"v": int64(300)}, &struct{ V int8 }{}, &struct{ V int8 }{}, true},
+		{"type mismatch", Row{"v": "a"}, &struct{ V int }{}, &struct{ V int }{}, true},
+		{"non-pointer", Row{}, hostLocation{}, hostLocation{}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.row.Decode(c.dest)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("wantErr=%v got=%v", c.wantErr, err)
+			}
+			if !c.wantErr && !reflect.DeepEqual(c.dest, c.want) {
+				t.Errorf("expected=%#v got=%#v", c.want, c.dest)
+			}
+		})
+	}
+}
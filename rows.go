@@ -1,8 +1,8 @@
 package timestreamdriver
 
 import (
+	"context"
 	"database/sql/driver"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/service/timestreamquery"
+	"github.com/aws/aws-sdk-go/service/timestreamquery/timestreamqueryiface"
 )
 
 var (
@@ -26,17 +27,93 @@ var (
 	stringType      = reflect.TypeOf("")
 	nullType        = reflect.TypeOf(nil)
 	timeType        = reflect.TypeOf(time.Time{})
+
+	intervalDayToSecondType = reflect.TypeOf(IntervalDayToSecond(0))
+	intervalYearToMonthType = reflect.TypeOf(IntervalYearToMonth{})
+	timeOfDayType           = reflect.TypeOf(TimeOfDay(0))
+
+	cancelQueryTimeout = time.Second * 10
 )
 
 type resultSet struct {
 	columns []*timestreamquery.ColumnInfo
 }
 
+// rows reads query results page by page.
+//
+// The next page is fetched when all rows of the current page are read,
+// or fetched in background while the current page is read if prefetch is enabled.
 type rows struct {
-	rs          resultSet
-	columnNames []string
-	rows        []*timestreamquery.Row
-	pos         int
+	ctx      context.Context
+	cancel   context.CancelFunc
+	tsq      timestreamqueryiface.TimestreamQueryAPI
+	input    *timestreamquery.QueryInput
+	prefetch bool
+	// maxBytesScanned is the limit of bytes scanned by the query; zero means unlimited
+	maxBytesScanned int64
+	// maxBytesMetered is the limit of bytes metered for the query; zero means unlimited
+	maxBytesMetered int64
+	rs              resultSet
+	columnNames     []string
+	rows            []*timestreamquery.Row
+	pos             int
+	nextToken       *string
+	queryID         *string
+	canceled        bool
+	stats           QueryStats
+	onStats         QueryStatsHandler
+	opts            scanOptions
+	// prefetched receives the next page; it is non-nil while prefetching
+	prefetched chan pageResult
+	// buf holds JSON values of array, row and timeseries columns of the current row
+	buf []byte
+}
+
+type pageResult struct {
+	out *timestreamquery.QueryOutput
+	err error
+}
+
+func newRows(ctx context.Context, tsq timestreamqueryiface.TimestreamQueryAPI, input *timestreamquery.QueryInput, cfg Config) *rows {
+	maxBytesScanned := cfg.MaxBytesScanned
+	if limit, ok := maxBytesScannedFrom(ctx); ok {
+		maxBytesScanned = limit
+	}
+	maxBytesMetered := cfg.MaxBytesMetered
+	if limit, ok := maxBytesMeteredFrom(ctx); ok {
+		maxBytesMetered = limit
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &rows{
+		ctx:             ctx,
+		cancel:          cancel,
+		tsq:             tsq,
+		input:           input,
+		prefetch:        cfg.Prefetch,
+		maxBytesScanned: maxBytesScanned,
+		maxBytesMetered: maxBytesMetered,
+		onStats:         queryStatsHandlerFrom(ctx),
+		opts:            scanOptions{loc: cfg.Location, timeFormat: cfg.TimeFormat},
+	}
+}
+
+// scanOptions configures conversion of results.
+type scanOptions struct {
+	loc        *time.Location
+	timeFormat TimeFormat
+}
+
+// nested returns the options for elements of arrays, rows and timeseries.
+// TimeFormat applies only to top-level columns so that nested times are always time.Time and TimeOfDay in the location.
+func (o scanOptions) nested() scanOptions {
+	return scanOptions{loc: o.loc}
+}
+
+func (o scanOptions) location() *time.Location {
+	if o.loc == nil {
+		return time.UTC
+	}
+	return o.loc
 }
 
 var _ interface {
@@ -62,19 +139,22 @@ func (r *rows) ColumnTypeScanType(index int) reflect.Type {
 	case timestreamquery.ScalarTypeBoolean:
 		return boolType
 	case timestreamquery.ScalarTypeDate:
-		return timeType
+		return r.timeScanType()
 	case timestreamquery.ScalarTypeDouble:
 		return doubleType
 	case timestreamquery.ScalarTypeInteger:
 		return intType
 	case timestreamquery.ScalarTypeIntervalDayToSecond:
-		return stringType
+		return intervalDayToSecondType
 	case timestreamquery.ScalarTypeIntervalYearToMonth:
-		return stringType
+		return intervalYearToMonthType
 	case timestreamquery.ScalarTypeTime:
-		return timeType
+		if r.opts.timeFormat == TimeFormatString {
+			return stringType
+		}
+		return timeOfDayType
 	case timestreamquery.ScalarTypeTimestamp:
-		return timeType
+		return r.timeScanType()
 	case timestreamquery.ScalarTypeVarchar:
 		return stringType
 	case timestreamquery.ScalarTypeUnknown:
@@ -84,6 +164,17 @@ func (r *rows) ColumnTypeScanType(index int) reflect.Type {
 	}
 }
 
+func (r *rows) timeScanType() reflect.Type {
+	switch r.opts.timeFormat {
+	case TimeFormatEpochNanos:
+		return bigintType
+	case TimeFormatString:
+		return stringType
+	default:
+		return timeType
+	}
+}
+
 func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
 	return getTSDataType(r.getColumn(index))
 }
@@ -99,18 +190,110 @@ func (r *rows) Columns() []string {
 	return r.columnNames
 }
 
-func (rows) Close() error {
+// Close stops paging. The query is cancelled if not all pages are fetched yet.
+func (r *rows) Close() error {
+	finished := r.nextToken == nil
+	r.cancel()
+	if r.prefetched != nil {
+		<-r.prefetched
+		r.prefetched = nil
+	}
+	r.rows = nil
+	r.nextToken = nil
+	if finished {
+		return nil
+	}
+	return r.cancelQuery()
+}
+
+// cancelQuery requests Timestream to cancel the running query so that it is no longer billed.
+func (r *rows) cancelQuery() error {
+	if r.queryID == nil || r.canceled {
+		return nil
+	}
+	r.canceled = true
+	ctx, cancel := context.WithTimeout(context.Background(), cancelQueryTimeout)
+	defer cancel()
+	_, err := r.tsq.CancelQueryWithContext(ctx, &timestreamquery.CancelQueryInput{QueryId: r.queryID})
+	return err
+}
+
+// fetch requests the page that nextToken points; nil nextToken means the first page.
+func (r *rows) fetch(nextToken *string) (*timestreamquery.QueryOutput, error) {
+	input := r.input
+	if nextToken != nil {
+		input = &timestreamquery.QueryInput{QueryString: r.input.QueryString, NextToken: nextToken}
+	}
+	return r.tsq.QueryWithContext(r.ctx, input)
+}
+
+func (r *rows) setPage(out *timestreamquery.QueryOutput) error {
+	if len(r.rs.columns) == 0 {
+		r.rs.columns = out.ColumnInfo
+	}
+	if r.queryID == nil {
+		r.queryID = out.QueryId
+	}
+	r.stats.update(out)
+	if r.onStats != nil {
+		r.onStats(r.stats)
+	}
+	// the last page is returned as is because the query has already finished and cancelling it saves nothing
+	if out.NextToken != nil {
+		if err := checkBudget(r.stats, r.maxBytesScanned, r.maxBytesMetered); err != nil {
+			r.rows = nil
+			r.nextToken = nil
+			_ = r.cancelQuery()
+			return err
+		}
+	}
+	r.rows = out.Rows
+	r.pos = 0
+	r.nextToken = out.NextToken
+	if r.prefetch && r.nextToken != nil {
+		ch := make(chan pageResult, 1)
+		r.prefetched = ch
+		nextToken := r.nextToken
+		go func() {
+			out, err := r.fetch(nextToken)
+			ch <- pageResult{out, err}
+		}()
+	}
 	return nil
 }
 
+func (r *rows) fetchNextPage() error {
+	var res pageResult
+	if r.prefetched != nil {
+		res = <-r.prefetched
+		r.prefetched = nil
+	} else {
+		res.out, res.err = r.fetch(r.nextToken)
+	}
+	if res.err != nil {
+		if r.ctx.Err() != nil {
+			_ = r.cancelQuery()
+		}
+		return res.err
+	}
+	return r.setPage(res.out)
+}
+
 func (r *rows) Next(dest []driver.Value) error {
-	if r.pos == len(r.rows) {
-		return io.EOF
+	// values of the previous row are valid only until Next is called, so buf is reused
+	r.buf = r.buf[:0]
+	for r.pos == len(r.rows) {
+		if r.nextToken == nil {
+			return io.EOF
+		}
+		if err := r.fetchNextPage(); err != nil {
+			return err
+		}
 	}
 	for i, datum := range r.rows[r.pos].Data {
 		columnInfo := r.getColumn(i)
 		var err error
-		dest[i], err = scanColumn(datum, columnInfo)
+		dest[i], err = r.scanColumn(datum, columnInfo)
 		if err != nil {
 			return err
 		}
@@ -119,29 +302,41 @@ func (r *rows) Next(dest []driver.Value) error {
 	return nil
 }
 
-func scanColumn(datum *timestreamquery.Datum, columnInfo *timestreamquery.ColumnInfo) (driver.Value, error) {
+func (r *rows) scanColumn(datum *timestreamquery.Datum, columnInfo *timestreamquery.ColumnInfo) (driver.Value, error) {
 	if datum.NullValue != nil && *datum.NullValue {
 		return nil, nil
 	}
-	if columnInfo.Type.ArrayColumnInfo != nil {
-		return scanArrayColumn(datum, columnInfo)
+	if columnInfo.Type.ArrayColumnInfo != nil || columnInfo.Type.RowColumnInfo != nil || columnInfo.Type.TimeSeriesMeasureValueColumnInfo != nil {
+		start := len(r.buf)
+		r.buf = appendColumnJSON(r.buf, &columnDatum{datum: datum, columnInfo: columnInfo, opts: r.opts.nested()})
+		return r.buf[start:len(r.buf):len(r.buf)], nil
 	}
 	if columnInfo.Type.ScalarType != nil {
-		return scanScalarColumn(datum, columnInfo)
+		v, err := scanScalarColumn(datum, columnInfo, r.opts)
+		if err != nil {
+			return nil, err
+		}
+		return resultValue(v, datum), nil
 	}
 	return nil, fmt.Errorf("column (%s) not handled", *columnInfo.Name)
 }
 
-type columnDatum struct {
-	Datum      *timestreamquery.Datum
-	ColumnInfo *timestreamquery.ColumnInfo
-}
-
-func scanArrayColumn(datum *timestreamquery.Datum, columnInfo *timestreamquery.ColumnInfo) (driver.Value, error) {
-	return json.Marshal(columnDatum{datum, columnInfo})
+// resultValue returns the value of the top-level scalar column given to database/sql.
+// Values that database/sql cannot convert into strings are replaced with the types that keep the text.
+func resultValue(v driver.Value, datum *timestreamquery.Datum) driver.Value {
+	switch v.(type) {
+	case TimeOfDay:
+		return timeOfDayResult(*datum.ScalarValue)
+	case IntervalDayToSecond:
+		return intervalResult(*datum.ScalarValue)
+	case IntervalYearToMonth:
+		return *datum.ScalarValue
+	default:
+		return v
+	}
 }
 
-func scanScalarColumn(datum *timestreamquery.Datum, columnInfo *timestreamquery.ColumnInfo) (driver.Value, error) {
+func scanScalarColumn(datum *timestreamquery.Datum, columnInfo *timestreamquery.ColumnInfo, opts scanOptions) (driver.Value, error) {
 	switch t := *columnInfo.Type.ScalarType; t {
 	case timestreamquery.ScalarTypeBigint:
 		i, ok := new(big.Int).SetString(*datum.ScalarValue, 10)
@@ -172,27 +367,32 @@ func scanScalarColumn(datum *timestreamquery.Datum, columnInfo *timestreamquery.
 		}
 		return parsed, nil
 	case timestreamquery.ScalarTypeDate:
-		parsed, err := parseDate(datum)
+		if opts.timeFormat == TimeFormatString {
+			return *datum.ScalarValue, nil
+		}
+		parsed, err := parseDate(datum, opts.location())
 		if err != nil {
 			return nil, err
 		}
-		return parsed, nil
+		return formatResultTime(parsed, opts), nil
 	case timestreamquery.ScalarTypeTimestamp:
+		if opts.timeFormat == TimeFormatString {
+			return *datum.ScalarValue, nil
+		}
 		parsed, err := parseTime(datum)
 		if err != nil {
 			return nil, err
 		}
-		return parsed, nil
+		return formatResultTime(parsed.In(opts.location()), opts), nil
 	case timestreamquery.ScalarTypeTime:
-		parsed, err := parseTime(datum)
-		if err != nil {
-			return nil, err
+		if opts.timeFormat == TimeFormatString {
+			return *datum.ScalarValue, nil
 		}
-		return parsed, nil
+		return parseTimeOfDay(*datum.ScalarValue)
 	case timestreamquery.ScalarTypeIntervalDayToSecond:
-		return *datum.ScalarValue, nil
+		return parseIntervalDayToSecond(*datum.ScalarValue)
 	case timestreamquery.ScalarTypeIntervalYearToMonth:
-		return *datum.ScalarValue, nil
+		return parseIntervalYearToMonth(*datum.ScalarValue)
 	case timestreamquery.ScalarTypeUnknown:
 		return nil, nil
 	default:
@@ -200,8 +400,17 @@ func scanScalarColumn(datum *timestreamquery.Datum, columnInfo *timestreamquery.
 	}
 }
 
-func parseDate(datum *timestreamquery.Datum) (time.Time, error) {
-	parsed, err := time.ParseInLocation(tsDateLayout, *datum.ScalarValue, time.UTC)
+// formatResultTime represents the time in the configured format.
+func formatResultTime(t time.Time, opts scanOptions) driver.Value {
+	if opts.timeFormat == TimeFormatEpochNanos {
+		return t.UnixNano()
+	}
+	return t
+}
+
+// parseDate parses DATE as midnight in loc.
+func parseDate(datum *timestreamquery.Datum, loc *time.Location) (time.Time, error) {
+	parsed, err := time.ParseInLocation(tsDateLayout, *datum.ScalarValue, loc)
 	if err != nil {
 		return time.Time{}, err
 	}
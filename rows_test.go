@@ -0,0 +1,250 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+// pagingServer serves query results split into pages; page N is requested with NextToken "N".
+type pagingServer struct {
+	pages [][]string
+	// delay is a time to wait before responding pages except the first
+	delay time.Duration
+
+	mu        sync.Mutex
+	requested []string
+	canceled  []string
+}
+
+func (s *pagingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Amz-Target") == "Timestream_20181101.CancelQuery" {
+		var input *timestreamquery.CancelQueryInput
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		s.mu.Lock()
+		s.canceled = append(s.canceled, aws.StringValue(input.QueryId))
+		s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(&timestreamquery.CancelQueryOutput{})
+		return
+	}
+	var input *timestreamquery.QueryInput
+	_ = json.NewDecoder(r.Body).Decode(&input)
+	token := aws.StringValue(input.NextToken)
+	s.mu.Lock()
+	s.requested = append(s.requested, token)
+	s.mu.Unlock()
+	if token != "" && s.delay > 0 {
+		select {
+		case <-time.After(s.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	page := 0
+	if token != "" {
+		page = int(token[0] - '0')
+	}
+	out := &timestreamquery.QueryOutput{
+		QueryId: aws.String("query-1"),
+		QueryStatus: &timestreamquery.QueryStatus{
+			CumulativeBytesScanned: aws.Int64(int64(page+1) * 100),
+			CumulativeBytesMetered: aws.Int64(int64(page+1) * 1000),
+			ProgressPercentage:     aws.Float64(float64(page+1) * 100 / float64(len(s.pages))),
+		},
+		ColumnInfo: []*timestreamquery.ColumnInfo{scalarColumn("str", timestreamquery.ScalarTypeVarchar)},
+		Rows:       []*timestreamquery.Row{},
+	}
+	for _, v := range s.pages[page] {
+		out.Rows = append(out.Rows, &timestreamquery.Row{Data: []*timestreamquery.Datum{{ScalarValue: aws.String(v)}}})
+	}
+	if page+1 < len(s.pages) {
+		out.NextToken = aws.String(string(rune('0' + page + 1)))
+	}
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+func (s *pagingServer) requestedTokens() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requested...)
+}
+
+func (s *pagingServer) canceledQueries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.canceled...)
+}
+
+func (s *pagingServer) waitCanceled(t *testing.T) []string {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 5)
+	for len(s.canceledQueries()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	return s.canceledQueries()
+}
+
+func newPagingTestDB(srv *httptest.Server, cfg Config) *sql.DB {
+	tsq := timestreamquery.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+	return sql.OpenDB(&connector{tsq: tsq, cfg: cfg})
+}
+
+func TestRows_Paging(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a", "b"}, {}, {"c"}, {"d"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{})
+
+	rows, err := db.QueryContext(context.Background(), `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if got := ps.requestedTokens(); !reflect.DeepEqual(got, []string{""}) {
+		t.Errorf("only the first page must be fetched before reading rows; requested=%#v", got)
+	}
+	var got []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a", "b", "c", "d"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("rows: expected=%#v got=%#v", expected, got)
+	}
+	if expected := []string{"", "1", "2", "3"}; !reflect.DeepEqual(ps.requestedTokens(), expected) {
+		t.Errorf("requested pages: expected=%#v got=%#v", expected, ps.requestedTokens())
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ps.canceledQueries(); len(got) != 0 {
+		t.Errorf("finished query must not be cancelled; canceled=%#v", got)
+	}
+}
+
+func TestRows_Paging_CloseEarly(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a", "b"}, {"c"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{})
+
+	rows, err := db.QueryContext(context.Background(), `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rows.Next() {
+		t.Fatal("no rows")
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := ps.requestedTokens(); !reflect.DeepEqual(got, []string{""}) {
+		t.Errorf("no more pages must be fetched after rows closed; requested=%#v", got)
+	}
+	if got := ps.canceledQueries(); !reflect.DeepEqual(got, []string{"query-1"}) {
+		t.Errorf("query must be cancelled; canceled=%#v", got)
+	}
+}
+
+func TestRows_Paging_ContextCanceled(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rows, err := db.QueryContext(ctx, `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal("no rows")
+	}
+	cancel()
+	if got := ps.waitCanceled(t); !reflect.DeepEqual(got, []string{"query-1"}) {
+		t.Errorf("query must be cancelled; canceled=%#v", got)
+	}
+}
+
+func TestRows_Paging_DeadlineExceeded(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}}, delay: time.Second * 5}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+	rows, err := db.QueryContext(ctx, `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err == nil {
+		t.Error("expected error but got nil")
+	}
+	if got := ps.waitCanceled(t); !reflect.DeepEqual(got, []string{"query-1"}) {
+		t.Errorf("query must be cancelled; canceled=%#v", got)
+	}
+}
+
+func TestRows_Paging_Prefetch(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {"b"}, {"c"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{Prefetch: true})
+
+	rows, err := db.QueryContext(context.Background(), `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	deadline := time.Now().Add(time.Second * 5)
+	for len(ps.requestedTokens()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	if got := ps.requestedTokens(); !reflect.DeepEqual(got, []string{"", "1"}) {
+		t.Errorf("the second page must be prefetched; requested=%#v", got)
+	}
+	var got []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"a", "b", "c"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("rows: expected=%#v got=%#v", expected, got)
+	}
+}
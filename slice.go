@@ -0,0 +1,91 @@
+package timestreamdriver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+)
+
+// ErrEmptySlice is an error indicates an empty slice is passed as a parameter expanded into a list.
+var ErrEmptySlice = errors.New("empty slice cannot be expanded into a list")
+
+// SliceForm is a form that SliceValue is rendered in.
+type SliceForm int
+
+const (
+	// SliceFormList renders the slice as a comma-separated list such as `'a', 'b'`; use with `IN (?)`.
+	SliceFormList SliceForm = iota
+	// SliceFormArray renders the slice as an ARRAY literal such as `ARRAY['a','b']`.
+	SliceFormArray
+)
+
+// SliceValue is a slice parameter rendered in the given form.
+//
+// Slices passed as parameters without wrapping are rendered as lists.
+type SliceValue struct {
+	Values interface{}
+	Form   SliceForm
+}
+
+var _ interface {
+	driver.Valuer
+	bareValue
+} = SliceValue{}
+
+// InList returns a parameter that renders values as a comma-separated list.
+func InList(values interface{}) SliceValue {
+	return SliceValue{Values: values, Form: SliceFormList}
+}
+
+// AsArray returns a parameter that renders values as an ARRAY literal.
+func AsArray(values interface{}) SliceValue {
+	return SliceValue{Values: values, Form: SliceFormArray}
+}
+
+func (sv SliceValue) Value() (driver.Value, error) {
+	rv := reflect.ValueOf(sv.Values)
+	if !isSliceParam(rv) {
+		return nil, errors.New("timestream: SliceValue must have a slice or an array")
+	}
+	buf := new(bytes.Buffer)
+	var err error
+	switch sv.Form {
+	case SliceFormArray:
+		err = writeArrayLiteral(buf, rv)
+	default:
+		err = writeListLiteral(buf, rv)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+func (SliceValue) IsBareValue() {}
+
+// isSliceParam reports whether the value is a slice or an array to be expanded; []byte is a string, not a slice.
+func isSliceParam(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return rv.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// writeListLiteral writes the slice as comma-separated literals; an empty slice is an error because `IN ()` is invalid.
+func writeListLiteral(buf *bytes.Buffer, rv reflect.Value) error {
+	if rv.Len() == 0 {
+		return ErrEmptySlice
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if err := writeArrayElement(buf, rv.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+func Test_interpolatesQuery_Slice(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   string
+		arg     interface{}
+		want    string
+		wantErr error
+	}{
+		{"strings", `host IN (?)`, []string{"a", "O'Reilly"}, `host IN ('a', 'O''Reilly')`, nil},
+		{"integers", `v IN (?)`, []int{1, 2}, `v IN (1, 2)`, nil},
+		{"array type", `v IN (?)`, [2]float64{0.5, 1}, `v IN (0.5, 1)`, nil},
+		{"explicit list", `v IN (?)`, InList([]bool{true}), `v IN (true)`, nil},
+		{"explicit array", `contains(?, v)`, AsArray([]string{"a", "b"}), `contains(ARRAY['a','b'], v)`, nil},
+		{"empty array", `cardinality(?)`, AsArray([]string{}), `cardinality(ARRAY[])`, nil},
+		{"empty", `v IN (?)`, []string{}, ``, ErrEmptySlice},
+		{"explicit empty list", `v IN (?)`, InList([]int{}), ``, ErrEmptySlice},
+		{"bytes are string", `v = ?`, []byte("a"), `v = 'a'`, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := interpolatesQuery(c.query, Config{}, []driver.NamedValue{{Ordinal: 1, Value: c.arg}})
+			if c.wantErr != nil {
+				if !errors.Is(err, c.wantErr) {
+					t.Fatalf("expected error %v but got %v", c.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+		})
+	}
+}
+
+func TestConn_QueryContext_SliceParameter(t *testing.T) {
+	db, qr := newRecordingTestDB(t, Config{})
+	ctx := context.Background()
+	rows, err := db.QueryContext(ctx, `SELECT * FROM t WHERE host IN ($hosts$) AND contains(?, az)`, sql.Named("hosts", []string{"a", "b"}), AsArray([]string{"1a"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if expected := `SELECT * FROM t WHERE host IN ('a', 'b') AND contains(ARRAY['1a'], az)`; qr.last() != expected {
+		t.Errorf("expected=%q got=%q", expected, qr.last())
+	}
+	if _, err := db.QueryContext(ctx, `SELECT * FROM t WHERE host IN (?)`, []string{}); !errors.Is(err, ErrEmptySlice) {
+		t.Errorf("expected ErrEmptySlice but got %v", err)
+	}
+}
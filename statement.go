@@ -6,25 +6,39 @@ import (
 )
 
 type stmt struct {
-	query string
-	cn    *conn
+	tmpl *queryTemplate
+	cn   *conn
 }
 
 var _ interface {
 	driver.Stmt
 	driver.StmtQueryContext
+	driver.StmtExecContext
+	driver.NamedValueChecker
 } = &stmt{}
 
 func (s *stmt) Close() error {
 	return nil
 }
 
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	return s.cn.CheckNamedValue(nv)
+}
+
 func (s *stmt) NumInput() int {
-	return -1
+	return s.tmpl.numInput()
 }
 
 func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
-	return nil, driver.ErrSkip
+	vs := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		vs[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return s.ExecContext(context.Background(), vs)
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.cn.execContext(ctx, s.tmpl, args)
 }
 
 func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
@@ -36,5 +50,9 @@ func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
 }
 
 func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
-	return s.cn.QueryContext(ctx, s.query, args)
+	enhancedQuery, err := s.tmpl.interpolate(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.cn.queryContext(ctx, enhancedQuery)
 }
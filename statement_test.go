@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -30,6 +31,63 @@ func TestStatement_Prepare_QueryContext_Scalar(t *testing.T) {
 	testRowsQueryScalar(t, rows)
 }
 
+func TestStatement_NumInput(t *testing.T) {
+	cases := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"no placeholders", "SELECT 1", 0},
+		{"positional", "SELECT 1 FROM table1 WHERE name = ? AND age = ?", 2},
+		{"named", "SELECT 1 FROM table1 WHERE name = $name$ OR nickname = $name$", 1},
+		{"mixed", "SELECT 1 FROM table1 WHERE name = $name$ AND age = ?", 2},
+		{"in literals", "SELECT '?' FROM table1 -- $name$", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			st, err := (&conn{}).Prepare(c.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := st.NumInput(); got != c.want {
+				t.Errorf("NumInput(): expected=%d got=%d", c.want, got)
+			}
+		})
+	}
+}
+
+func TestStatement_Prepare_Invalid(t *testing.T) {
+	if _, err := (&conn{}).Prepare("SELECT 'yuno"); err == nil {
+		t.Error("expected error but got nil")
+	}
+}
+
+func TestStatement_Prepare_QueryContext_ArgumentsMismatch(t *testing.T) {
+	db, close := prepareTestDB()
+	defer close()
+	ctx := context.Background()
+	st, err := db.PrepareContext(ctx, `SELECT 1 FROM table1 WHERE name = ?`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	if _, err := st.QueryContext(ctx); err == nil {
+		t.Error("expected error for too few parameters but got nil")
+	}
+	if _, err := st.QueryContext(ctx, "me", "you"); err == nil {
+		t.Error("expected error for too many parameters but got nil")
+	}
+}
+
+func TestConn_QueryContext_TooManyParameters(t *testing.T) {
+	db, close := prepareTestDB()
+	defer close()
+	_, err := db.QueryContext(context.Background(), `SELECT 1 FROM table1 WHERE name = ?`, "me", "you")
+	if !errors.Is(err, ErrTooManyParameters) {
+		t.Errorf("expected ErrTooManyParameters but got %v", err)
+	}
+}
+
 func prepareTestDB() (*sql.DB, func()) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		_ = json.NewEncoder(w).Encode(scalarOutput())
@@ -42,5 +100,5 @@ func prepareTestDB() (*sql.DB, func()) {
 		},
 	})))
 
-	return sql.OpenDB(&connector{tsq}), func() { srv.Close() }
+	return sql.OpenDB(&connector{tsq: tsq}), func() { srv.Close() }
 }
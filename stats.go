@@ -0,0 +1,51 @@
+package timestreamdriver
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+// QueryStats is the progress of a query that Timestream reports with each page of results.
+type QueryStats struct {
+	// QueryID identifies the query. It is useful to log and tell AWS support.
+	QueryID string
+	// PagesFetched is the number of pages fetched so far.
+	PagesFetched int
+	// BytesScanned is the cumulative number of bytes scanned by the query.
+	BytesScanned int64
+	// BytesMetered is the cumulative number of bytes metered (billed) for the query.
+	BytesMetered int64
+	// ProgressPercentage is the progress of the query reported by Timestream.
+	ProgressPercentage float64
+}
+
+// QueryStatsHandler is a function that receives QueryStats each time a page of results is fetched.
+type QueryStatsHandler func(stats QueryStats)
+
+type queryStatsHandlerKey struct{}
+
+// WithQueryStatsHandler returns a new context that handler receives QueryStats of queries issued with the context.
+//
+// The handler is called in the goroutine that reads rows.
+func WithQueryStatsHandler(ctx context.Context, handler QueryStatsHandler) context.Context {
+	return context.WithValue(ctx, queryStatsHandlerKey{}, handler)
+}
+
+func queryStatsHandlerFrom(ctx context.Context) QueryStatsHandler {
+	handler, _ := ctx.Value(queryStatsHandlerKey{}).(QueryStatsHandler)
+	return handler
+}
+
+func (s *QueryStats) update(out *timestreamquery.QueryOutput) {
+	s.PagesFetched++
+	if out.QueryId != nil {
+		s.QueryID = *out.QueryId
+	}
+	if status := out.QueryStatus; status != nil {
+		s.BytesScanned = aws.Int64Value(status.CumulativeBytesScanned)
+		s.BytesMetered = aws.Int64Value(status.CumulativeBytesMetered)
+		s.ProgressPercentage = aws.Float64Value(status.ProgressPercentage)
+	}
+}
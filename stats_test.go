@@ -0,0 +1,45 @@
+package timestreamdriver
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWithQueryStatsHandler(t *testing.T) {
+	ps := &pagingServer{pages: [][]string{{"a"}, {}, {"b"}, {"c"}}}
+	srv := httptest.NewServer(ps)
+	defer srv.Close()
+	db := newPagingTestDB(srv, Config{})
+
+	var got []QueryStats
+	ctx := WithQueryStatsHandler(context.Background(), func(stats QueryStats) {
+		got = append(got, stats)
+	})
+	rows, err := db.QueryContext(ctx, `SELECT str FROM table1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []QueryStats{
+		{QueryID: "query-1", PagesFetched: 1, BytesScanned: 100, BytesMetered: 1000, ProgressPercentage: 25},
+		{QueryID: "query-1", PagesFetched: 2, BytesScanned: 200, BytesMetered: 2000, ProgressPercentage: 50},
+		{QueryID: "query-1", PagesFetched: 3, BytesScanned: 300, BytesMetered: 3000, ProgressPercentage: 75},
+		{QueryID: "query-1", PagesFetched: 4, BytesScanned: 400, BytesMetered: 4000, ProgressPercentage: 100},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("QueryStats:\nexpected=%#v\n     got=%#v", expected, got)
+	}
+}
+
+func TestWithQueryStatsHandler_NoHandler(t *testing.T) {
+	if handler := queryStatsHandlerFrom(context.Background()); handler != nil {
+		t.Errorf("expected no handler but got %p", handler)
+	}
+}
@@ -0,0 +1,116 @@
+package timestreamdriver
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeOfDay is a value of TIME; the elapsed time since midnight.
+//
+// TIME columns can be scanned into this type, time.Duration (the elapsed time) and strings (the text as returned by Timestream).
+type TimeOfDay time.Duration
+
+var _ interface {
+	driver.Valuer
+	sql.Scanner
+	bareValue
+	fmt.Stringer
+} = new(TimeOfDay)
+
+// NewTimeOfDay returns TimeOfDay of the clock.
+func NewTimeOfDay(hour, minute, sec, nsec int) TimeOfDay {
+	return TimeOfDay(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(sec)*time.Second + time.Duration(nsec))
+}
+
+func (t TimeOfDay) Hour() int {
+	return int(time.Duration(t) / time.Hour)
+}
+
+func (t TimeOfDay) Minute() int {
+	return int(time.Duration(t) % time.Hour / time.Minute)
+}
+
+func (t TimeOfDay) Second() int {
+	return int(time.Duration(t) % time.Minute / time.Second)
+}
+
+func (t TimeOfDay) Nanosecond() int {
+	return int(time.Duration(t) % time.Second)
+}
+
+// On returns the time of the day on the date of d in the location of d.
+func (t TimeOfDay) On(d time.Time) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), d.Location())
+}
+
+// String returns the time in the form of Timestream results such as `12:34:56.000000000`.
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d.%09d", t.Hour(), t.Minute(), t.Second(), t.Nanosecond())
+}
+
+// Value renders the time as a TIME literal.
+func (t TimeOfDay) Value() (driver.Value, error) {
+	if t < 0 || time.Duration(t) >= day {
+		return nil, fmt.Errorf("time of day out of range: %s", time.Duration(t))
+	}
+	return "TIME '" + t.String() + "'", nil
+}
+
+func (TimeOfDay) IsBareValue() {}
+
+func (t *TimeOfDay) Scan(src interface{}) error {
+	switch src := src.(type) {
+	case TimeOfDay:
+		*t = src
+		return nil
+	case timeOfDayResult:
+		parsed, err := parseTimeOfDay(string(src))
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	case string:
+		parsed, err := parseTimeOfDay(src)
+		if err != nil {
+			return err
+		}
+		*t = parsed
+		return nil
+	case time.Time:
+		*t = NewTimeOfDay(src.Hour(), src.Minute(), src.Second(), src.Nanosecond())
+		return nil
+	default:
+		return fmt.Errorf("timestream: cannot convert %T into TimeOfDay", src)
+	}
+}
+
+// parseTimeOfDay parses the form of `HH:MM:SS.NNNNNNNNN`; the date part is ignored if given.
+func parseTimeOfDay(s string) (TimeOfDay, error) {
+	if _, clock, ok := strings.Cut(s, " "); ok {
+		s = clock
+	}
+	parsed, err := time.Parse("15:04:05.999999999", s)
+	if err != nil {
+		return 0, err
+	}
+	return NewTimeOfDay(parsed.Hour(), parsed.Minute(), parsed.Second(), parsed.Nanosecond()), nil
+}
+
+// timeOfDayResult is a value of TIME column given to database/sql.
+//
+// It is the text as returned by Timestream so that database/sql scans it into strings as is,
+// and String returns the elapsed nanoseconds so that database/sql scans it into time.Duration and integers.
+type timeOfDayResult string
+
+func (r timeOfDayResult) String() string {
+	parsed, err := parseTimeOfDay(string(r))
+	if err != nil {
+		return string(r)
+	}
+	return strconv.FormatInt(int64(parsed), 10)
+}
@@ -0,0 +1,205 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+func TestTimeOfDay(t *testing.T) {
+	cases := []struct {
+		text    string
+		want    TimeOfDay
+		wantErr bool
+	}{
+		{"12:34:56.000000000", NewTimeOfDay(12, 34, 56, 0), false},
+		{"00:00:00.000000001", NewTimeOfDay(0, 0, 0, 1), false},
+		{"23:59:59.999999999", NewTimeOfDay(23, 59, 59, 999999999), false},
+		{"2010-01-01 01:02:03.000000000", NewTimeOfDay(1, 2, 3, 0), false},
+		{"25:00:00.000000000", 0, true},
+	}
+	for _, c := range cases {
+		t.Run(c.text, func(t *testing.T) {
+			var got TimeOfDay
+			err := got.Scan(c.text)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("wantErr=%v got=%v", c.wantErr, err)
+			}
+			if c.wantErr {
+				return
+			}
+			if got != c.want {
+				t.Errorf("expected=%s got=%s", c.want, got)
+			}
+		})
+	}
+
+	tod := NewTimeOfDay(1, 2, 3, 4)
+	if s := tod.String(); s != "01:02:03.000000004" {
+		t.Errorf("String(): got=%q", s)
+	}
+	if v, err := tod.Value(); err != nil || v != "TIME '01:02:03.000000004'" {
+		t.Errorf("Value(): got=%v err=%v", v, err)
+	}
+	if _, err := TimeOfDay(25 * time.Hour).Value(); err == nil {
+		t.Error("Value(): expected an error for out of range")
+	}
+	if on, expected := tod.On(time.Date(2020, 1, 2, 23, 0, 0, 0, tokyo)), time.Date(2020, 1, 2, 1, 2, 3, 4, tokyo); !on.Equal(expected) {
+		t.Errorf("On(): expected=%s got=%s", expected, on)
+	}
+}
+
+func TestRows_TimeOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&timestreamquery.QueryOutput{
+			ColumnInfo: []*timestreamquery.ColumnInfo{
+				scalarColumn("ts", timestreamquery.ScalarTypeTimestamp),
+				scalarColumn("dt", timestreamquery.ScalarTypeDate),
+				scalarColumn("tm", timestreamquery.ScalarTypeTime),
+			},
+			Rows: []*timestreamquery.Row{{Data: []*timestreamquery.Datum{
+				{ScalarValue: aws.String("2020-01-01 15:00:00.000000000")},
+				{ScalarValue: aws.String("2020-01-02")},
+				{ScalarValue: aws.String("12:00:00.000000000")},
+			}}},
+		})
+	}))
+	defer srv.Close()
+	tsq := timestreamquery.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+	queryRow := func(t *testing.T, cfg Config, dest ...interface{}) {
+		t.Helper()
+		db := sql.OpenDB(&connector{tsq: tsq, cfg: cfg})
+		if err := db.QueryRowContext(context.Background(), `SELECT ts, dt, tm`).Scan(dest...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("location", func(t *testing.T) {
+		var (
+			ts, dt time.Time
+			tm     TimeOfDay
+		)
+		queryRow(t, Config{Location: tokyo}, &ts, &dt, &tm)
+		if expected := time.Date(2020, 1, 2, 0, 0, 0, 0, tokyo); !ts.Equal(expected) || ts.Location() != tokyo {
+			t.Errorf("ts: expected=%s got=%s", expected, ts)
+		}
+		if expected := time.Date(2020, 1, 2, 0, 0, 0, 0, tokyo); !dt.Equal(expected) {
+			t.Errorf("dt: expected=%s got=%s", expected, dt)
+		}
+		if expected := NewTimeOfDay(12, 0, 0, 0); tm != expected {
+			t.Errorf("tm: expected=%s got=%s", expected, tm)
+		}
+	})
+	t.Run("time into other types", func(t *testing.T) {
+		var (
+			s  string
+			ns sql.NullString
+			d  time.Duration
+			n  int64
+		)
+		for _, dest := range []interface{}{&s, &ns, &d, &n} {
+			queryRow(t, Config{}, new(interface{}), new(interface{}), dest)
+		}
+		if s != "12:00:00.000000000" || ns.String != "12:00:00.000000000" || !ns.Valid {
+			t.Errorf("expected the text but got string=%q NullString=%#v", s, ns)
+		}
+		if d != 12*time.Hour || n != int64(12*time.Hour) {
+			t.Errorf("expected the elapsed time but got Duration=%s int64=%d", d, n)
+		}
+	})
+	t.Run("epochNanos", func(t *testing.T) {
+		var (
+			ts, dt int64
+			tm     time.Duration
+		)
+		queryRow(t, Config{TimeFormat: TimeFormatEpochNanos}, &ts, &dt, &tm)
+		if expected := time.Date(2020, 1, 1, 15, 0, 0, 0, time.UTC).UnixNano(); ts != expected {
+			t.Errorf("ts: expected=%d got=%d", expected, ts)
+		}
+		if expected := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC).UnixNano(); dt != expected {
+			t.Errorf("dt: expected=%d got=%d", expected, dt)
+		}
+		if tm != 12*time.Hour {
+			t.Errorf("tm: expected=%s got=%s", 12*time.Hour, tm)
+		}
+	})
+	t.Run("string", func(t *testing.T) {
+		var ts, dt, tm string
+		queryRow(t, Config{TimeFormat: TimeFormatString}, &ts, &dt, &tm)
+		if ts != "2020-01-01 15:00:00.000000000" || dt != "2020-01-02" || tm != "12:00:00.000000000" {
+			t.Errorf("unexpected: ts=%q dt=%q tm=%q", ts, dt, tm)
+		}
+	})
+}
+
+func TestRows_TimeOptions_Nested(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&timestreamquery.QueryOutput{
+			ColumnInfo: []*timestreamquery.ColumnInfo{
+				arrayColumn("tss", timestreamquery.ScalarTypeTimestamp),
+				rowColumn("r", scalarColumn("ts", timestreamquery.ScalarTypeTimestamp), scalarColumn("tm", timestreamquery.ScalarTypeTime)),
+			},
+			Rows: []*timestreamquery.Row{{Data: []*timestreamquery.Datum{
+				arrayValue("2020-01-01 15:00:00.000000000"),
+				rowDatum(scalarDatum("2020-01-01 15:00:00.000000000"), scalarDatum("12:00:00.000000000")),
+			}}},
+		})
+	}))
+	defer srv.Close()
+	tsq := timestreamquery.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+	expected := time.Date(2020, 1, 2, 0, 0, 0, 0, tokyo)
+	for _, format := range []TimeFormat{TimeFormatTime, TimeFormatEpochNanos, TimeFormatString} {
+		t.Run(string(format), func(t *testing.T) {
+			db := sql.OpenDB(&connector{tsq: tsq, cfg: Config{Location: tokyo, TimeFormat: format}})
+			var (
+				tss ArrayOf[time.Time]
+				r   Row
+			)
+			if err := db.QueryRowContext(context.Background(), `SELECT tss, r`).Scan(&tss, &r); err != nil {
+				t.Fatal(err)
+			}
+			if len(tss) != 1 || !tss[0].Equal(expected) || tss[0].Location().String() != tokyo.String() {
+				t.Errorf("array: expected=[%s] got=%v", expected, tss)
+			}
+			if ts, ok := r["ts"].(time.Time); !ok || !ts.Equal(expected) || ts.Location().String() != tokyo.String() {
+				t.Errorf("row ts: expected=%s got=%#v", expected, r["ts"])
+			}
+			if tm := r["tm"]; tm != NewTimeOfDay(12, 0, 0, 0) {
+				t.Errorf("row tm: expected=12:00:00 got=%#v", tm)
+			}
+
+			// the values copied by database/sql keep the location
+			var raw []byte
+			if err := db.QueryRowContext(context.Background(), `SELECT tss, r`).Scan(&raw, new(interface{})); err != nil {
+				t.Fatal(err)
+			}
+			if err := tss.Scan(raw); err != nil {
+				t.Fatal(err)
+			}
+			if len(tss) != 1 || !tss[0].Equal(expected) || tss[0].Location().String() != tokyo.String() {
+				t.Errorf("copied array: expected=[%s] got=%v", expected, tss)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package timestreamdriver
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+// TimeSeriesPoint is a data point of TimeSeries.
+type TimeSeriesPoint struct {
+	Time time.Time
+	// Value is converted in the same way as scalar columns; nil if the value is NULL.
+	Value interface{}
+}
+
+// TimeSeries is a value of TIMESERIES column (e.g. the result of CREATE_TIME_SERIES or INTERPOLATE_*) that scannable by database/sql.
+// A NULL column is scanned as nil TimeSeries.
+type TimeSeries []TimeSeriesPoint
+
+var _ sql.Scanner = &TimeSeries{}
+
+func (ts *TimeSeries) Scan(src interface{}) error {
+	if src == nil {
+		*ts = nil
+		return nil
+	}
+	cd, err := columnDatumOf(src)
+	if err != nil {
+		return err
+	}
+	if cd.columnInfo.Type.TimeSeriesMeasureValueColumnInfo == nil {
+		return fmt.Errorf("timestream: cannot convert non-timeseries column into TimeSeries")
+	}
+	v, err := datumValue(cd.datum, cd.columnInfo, cd.opts)
+	if err != nil {
+		return err
+	}
+	series, _ := v.(TimeSeries)
+	*ts = series
+	return nil
+}
+
+// Times returns times of the points.
+func (ts TimeSeries) Times() []time.Time {
+	xs := make([]time.Time, len(ts))
+	for i, p := range ts {
+		xs[i] = p.Time
+	}
+	return xs
+}
+
+// Float64s returns values of the series of double measures.
+func (ts TimeSeries) Float64s() ([]float64, error) {
+	xs := make([]float64, len(ts))
+	for i, p := range ts {
+		v, ok := p.Value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("timestream: point #%d: cannot convert %T into float64", i, p.Value)
+		}
+		xs[i] = v
+	}
+	return xs, nil
+}
+
+// Int64s returns values of the series of bigint measures.
+func (ts TimeSeries) Int64s() ([]int64, error) {
+	xs := make([]int64, len(ts))
+	for i, p := range ts {
+		v, ok := p.Value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("timestream: point #%d: cannot convert %T into int64", i, p.Value)
+		}
+		xs[i] = v
+	}
+	return xs, nil
+}
+
+// Strings returns values of the series of varchar measures.
+func (ts TimeSeries) Strings() ([]string, error) {
+	xs := make([]string, len(ts))
+	for i, p := range ts {
+		v, ok := p.Value.(string)
+		if !ok {
+			return nil, fmt.Errorf("timestream: point #%d: cannot convert %T into string", i, p.Value)
+		}
+		xs[i] = v
+	}
+	return xs, nil
+}
+
+func timeSeriesValue(datum *timestreamquery.Datum, columnInfo *timestreamquery.ColumnInfo, opts scanOptions) (TimeSeries, error) {
+	series := make(TimeSeries, len(datum.TimeSeriesValue))
+	for i, point := range datum.TimeSeriesValue {
+		if point.Time == nil {
+			return nil, fmt.Errorf("timestream: point #%d has no time", i)
+		}
+		t, err := time.ParseInLocation(tsTimeLayout, *point.Time, time.UTC)
+		if err != nil {
+			return nil, err
+		}
+		v, err := datumValue(point.Value, columnInfo, opts)
+		if err != nil {
+			return nil, err
+		}
+		series[i] = TimeSeriesPoint{Time: t.In(opts.location()), Value: v}
+	}
+	return series, nil
+}
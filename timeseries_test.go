@@ -0,0 +1,112 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+)
+
+func timeSeriesColumn(name, typ string) *timestreamquery.ColumnInfo {
+	return &timestreamquery.ColumnInfo{
+		Name: aws.String(name),
+		Type: &timestreamquery.Type{
+			TimeSeriesMeasureValueColumnInfo: &timestreamquery.ColumnInfo{
+				Type: &timestreamquery.Type{ScalarType: aws.String(typ)}}}}
+}
+
+func timeSeriesDatum(points ...string) *timestreamquery.Datum {
+	dm := &timestreamquery.Datum{TimeSeriesValue: []*timestreamquery.TimeSeriesDataPoint{}}
+	for i := 0; i < len(points); i += 2 {
+		dm.TimeSeriesValue = append(dm.TimeSeriesValue, &timestreamquery.TimeSeriesDataPoint{
+			Time:  aws.String(points[i]),
+			Value: &timestreamquery.Datum{ScalarValue: aws.String(points[i+1])},
+		})
+	}
+	return dm
+}
+
+func TestConn_QueryContext_TimeSeries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(&timestreamquery.QueryOutput{
+			ColumnInfo: []*timestreamquery.ColumnInfo{
+				scalarColumn("host", timestreamquery.ScalarTypeVarchar),
+				timeSeriesColumn("cpu", timestreamquery.ScalarTypeDouble),
+				timeSeriesColumn("count", timestreamquery.ScalarTypeBigint),
+			},
+			Rows: []*timestreamquery.Row{
+				{Data: []*timestreamquery.Datum{
+					{ScalarValue: aws.String("host-1")},
+					timeSeriesDatum("2020-01-01 00:00:00.000000000", "0.5", "2020-01-01 00:01:00.000000000", "0.75"),
+					timeSeriesDatum("2020-01-01 00:00:00.000000000", "3"),
+				}},
+				{Data: []*timestreamquery.Datum{
+					{ScalarValue: aws.String("host-2")},
+					nullDatum,
+					nullDatum,
+				}},
+			},
+		})
+	}))
+	defer srv.Close()
+	tsq := timestreamquery.New(session.Must(session.NewSessionWithOptions(session.Options{
+		Config: aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(srv.URL),
+			Credentials: credentials.NewStaticCredentials("id", "secret", "token"),
+		},
+	})))
+	db := sql.OpenDB(&connector{tsq: tsq})
+
+	rows, err := db.QueryContext(context.Background(), `SELECT host, CREATE_TIME_SERIES(time, cpu), CREATE_TIME_SERIES(time, count) FROM t GROUP BY host`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	var (
+		host       string
+		cpu, count TimeSeries
+	)
+	if err := rows.Scan(&host, &cpu, &count); err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectedCPU := TimeSeries{{Time: t0, Value: 0.5}, {Time: t0.Add(time.Minute), Value: 0.75}}
+	if !reflect.DeepEqual(cpu, expectedCPU) {
+		t.Errorf("expected=%#v got=%#v", expectedCPU, cpu)
+	}
+	if expected := []time.Time{t0, t0.Add(time.Minute)}; !reflect.DeepEqual(cpu.Times(), expected) {
+		t.Errorf("Times(): expected=%v got=%v", expected, cpu.Times())
+	}
+	if got, err := cpu.Float64s(); err != nil || !reflect.DeepEqual(got, []float64{0.5, 0.75}) {
+		t.Errorf("Float64s(): got=%v err=%v", got, err)
+	}
+	if got, err := count.Int64s(); err != nil || !reflect.DeepEqual(got, []int64{3}) {
+		t.Errorf("Int64s(): got=%v err=%v", got, err)
+	}
+	if _, err := cpu.Strings(); err == nil {
+		t.Error("Strings(): expected an error for double series")
+	}
+
+	if !rows.Next() {
+		t.Fatal(rows.Err())
+	}
+	if err := rows.Scan(&host, &cpu, &count); err != nil {
+		t.Fatal(err)
+	}
+	if cpu != nil || count != nil {
+		t.Errorf("expected NULL series scanned as nil but got cpu=%#v count=%#v", cpu, count)
+	}
+}
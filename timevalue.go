@@ -0,0 +1,77 @@
+package timestreamdriver
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TimeForm is a form that TimeValue is rendered in.
+type TimeForm int
+
+const (
+	// TimeFormString renders the time as a string literal such as `'2006-01-02 15:04:05.999999999'`; time.Time parameters are rendered in this form.
+	TimeFormString TimeForm = iota
+	// TimeFormTimestamp renders the time as a TIMESTAMP literal such as `TIMESTAMP '2006-01-02 15:04:05.999999999'`.
+	TimeFormTimestamp
+	// TimeFormDate renders the date of the time as a DATE literal such as `DATE '2006-01-02'`.
+	TimeFormDate
+	// TimeFormNanoseconds renders the time as `from_nanoseconds(<epoch nanoseconds>)`.
+	TimeFormNanoseconds
+	// TimeFormMilliseconds renders the time as `from_milliseconds(<epoch milliseconds>)`.
+	TimeFormMilliseconds
+)
+
+// TimeValue is a time parameter rendered in the given form.
+// Times are converted into UTC before formatting as Timestream has no time zone.
+type TimeValue struct {
+	Time time.Time
+	Form TimeForm
+}
+
+var _ interface {
+	driver.Valuer
+	bareValue
+} = TimeValue{}
+
+// AsTimestamp returns a parameter that renders t as a TIMESTAMP literal.
+func AsTimestamp(t time.Time) TimeValue {
+	return TimeValue{Time: t, Form: TimeFormTimestamp}
+}
+
+// AsDate returns a parameter that renders the date of t in UTC as a DATE literal.
+func AsDate(t time.Time) TimeValue {
+	return TimeValue{Time: t, Form: TimeFormDate}
+}
+
+// FromNanoseconds returns a parameter that renders t as from_nanoseconds() call.
+func FromNanoseconds(t time.Time) TimeValue {
+	return TimeValue{Time: t, Form: TimeFormNanoseconds}
+}
+
+// FromMilliseconds returns a parameter that renders t as from_milliseconds() call.
+// The time is truncated to milliseconds.
+func FromMilliseconds(t time.Time) TimeValue {
+	return TimeValue{Time: t, Form: TimeFormMilliseconds}
+}
+
+func (tv TimeValue) Value() (driver.Value, error) {
+	t := tv.Time.UTC()
+	switch tv.Form {
+	case TimeFormString:
+		return "'" + t.Format(tsTimeLayout) + "'", nil
+	case TimeFormTimestamp:
+		return "TIMESTAMP '" + t.Format(tsTimeLayout) + "'", nil
+	case TimeFormDate:
+		return "DATE '" + t.Format(tsDateLayout) + "'", nil
+	case TimeFormNanoseconds:
+		return "from_nanoseconds(" + strconv.FormatInt(t.UnixNano(), 10) + ")", nil
+	case TimeFormMilliseconds:
+		return "from_milliseconds(" + strconv.FormatInt(t.UnixMilli(), 10) + ")", nil
+	default:
+		return nil, fmt.Errorf("unknown time form: %d", tv.Form)
+	}
+}
+
+func (TimeValue) IsBareValue() {}
@@ -0,0 +1,62 @@
+package timestreamdriver
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+)
+
+func TestTimeValue_Value(t *testing.T) {
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+	ts := time.Date(2020, 1, 2, 8, 4, 5, 123456789, jst)
+	cases := []struct {
+		name string
+		arg  TimeValue
+		want string
+	}{
+		{"string", TimeValue{Time: ts}, "'2020-01-01 23:04:05.123456789'"},
+		{"timestamp", AsTimestamp(ts), "TIMESTAMP '2020-01-01 23:04:05.123456789'"},
+		{"date", AsDate(ts), "DATE '2020-01-01'"},
+		{"nanoseconds", FromNanoseconds(ts), "from_nanoseconds(1577919845123456789)"},
+		{"milliseconds", FromMilliseconds(ts), "from_milliseconds(1577919845123)"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := c.arg.Value()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != c.want {
+				t.Errorf("expected=%q got=%q", c.want, got)
+			}
+		})
+	}
+	if _, err := (TimeValue{Time: ts, Form: TimeForm(-1)}).Value(); err == nil {
+		t.Error("expected an error for unknown form")
+	}
+}
+
+func Test_interpolatesQuery_TimeInUTC(t *testing.T) {
+	jst := time.FixedZone("Asia/Tokyo", 9*60*60)
+	got, err := interpolatesQuery(`SELECT * FROM t WHERE time > ?`, Config{}, []driver.NamedValue{{Ordinal: 1, Value: time.Date(2020, 1, 2, 9, 0, 0, 0, jst)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `SELECT * FROM t WHERE time > '2020-01-02 00:00:00'`; got != expected {
+		t.Errorf("expected=%q got=%q", expected, got)
+	}
+}
+
+func TestConn_QueryContext_TimeParameter(t *testing.T) {
+	db, qr := newRecordingTestDB(t, Config{})
+	ts := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	rows, err := db.QueryContext(context.Background(), `SELECT * FROM t WHERE time BETWEEN ? AND ? AND d = ?`, FromMilliseconds(ts), AsTimestamp(ts.Add(time.Hour)), AsDate(ts))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if expected := `SELECT * FROM t WHERE time BETWEEN from_milliseconds(1577923200000) AND TIMESTAMP '2020-01-02 01:00:00' AND d = DATE '2020-01-02'`; qr.last() != expected {
+		t.Errorf("expected=%q got=%q", expected, qr.last())
+	}
+}
@@ -0,0 +1,540 @@
+package timestreamdriver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite/timestreamwriteiface"
+)
+
+var (
+	// maxRecordsPerWrite is the maximum number of records that WriteRecords accepts at once.
+	maxRecordsPerWrite = 100
+
+	tagName = "ts"
+
+	timeUnits = map[string]string{
+		"s":  timestreamwrite.TimeUnitSeconds,
+		"ms": timestreamwrite.TimeUnitMilliseconds,
+		"us": timestreamwrite.TimeUnitMicroseconds,
+		"ns": timestreamwrite.TimeUnitNanoseconds,
+	}
+	timeUnitDurations = map[string]time.Duration{
+		timestreamwrite.TimeUnitSeconds:      time.Second,
+		timestreamwrite.TimeUnitMilliseconds: time.Millisecond,
+		timestreamwrite.TimeUnitMicroseconds: time.Microsecond,
+		timestreamwrite.TimeUnitNanoseconds:  time.Nanosecond,
+	}
+	measureValueTypes = map[string]string{
+		"double":    timestreamwrite.MeasureValueTypeDouble,
+		"bigint":    timestreamwrite.MeasureValueTypeBigint,
+		"varchar":   timestreamwrite.MeasureValueTypeVarchar,
+		"boolean":   timestreamwrite.MeasureValueTypeBoolean,
+		"timestamp": timestreamwrite.MeasureValueTypeTimestamp,
+	}
+
+	schemaCache sync.Map // map[reflect.Type]*recordSchema
+)
+
+// Writer writes Go structs into a Timestream table as records.
+//
+// Fields of the struct are mapped by `ts` tag:
+//
+//	type Metric struct {
+//		Time   time.Time `ts:"time,ms"`
+//		Host   string    `ts:"dimension,name=host"`
+//		CPU    float64   `ts:"measure,double,name=cpu"`
+//		Memory int64     `ts:"measure,bigint,name=memory"`
+//	}
+//
+// The first element of the tag is a kind of the field:
+//
+//	time          the time of the record; time.Time or integers of epoch time. The option is a unit: s, ms (default), us or ns.
+//	dimension     a dimension.
+//	measure       a measure. The option is a type: double, bigint, varchar, boolean or timestamp; inferred from the field type if omitted.
+//	measure_name  the measure name of multi-measure records.
+//	-             the field is ignored as untagged fields.
+//
+// `name=` option gives the name of dimensions and measures; the field name is used if omitted.
+// A struct that has one measure is written as a single-measure record named after the measure,
+// and a struct that has multiple measures is written as a multi-measure record.
+type Writer struct {
+	tsw      timestreamwriteiface.TimestreamWriteAPI
+	database string
+	table    string
+
+	// MultiMeasureName is the measure name of multi-measure records built from structs that have no measure_name field.
+	MultiMeasureName string
+	// DeadLetter receives records that Timestream rejected, if given.
+	DeadLetter DeadLetterSink
+}
+
+// NewWriter returns a new Writer that writes into the table with the region, endpoint and credentials given by Config.
+// Use ParseDSN to build Config from the same DSN as database/sql.
+// Empty database and table fall back to the default database and table of Config.
+func NewWriter(cfg *Config, database, table string) (*Writer, error) {
+	if database == "" {
+		database = cfg.Database
+	}
+	if table == "" {
+		table = cfg.Table
+	}
+	if database == "" || table == "" {
+		return nil, errors.New("database and table are required")
+	}
+	ses, err := newSession(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{tsw: timestreamwrite.New(ses), database: database, table: table}, nil
+}
+
+// Write writes v as records and returns the number of ingested records.
+// v must be a struct, a pointer to struct or a slice of them.
+//
+// If Timestream rejects some records, *RejectedRecordsError is returned and the records are put into DeadLetter.
+// If writing fails on the way, *UnwrittenRecordsError that holds the records not written is returned, or wrapped by *RejectedRecordsError.
+func (w *Writer) Write(ctx context.Context, v interface{}) (int64, error) {
+	records, err := w.Records(v)
+	if err != nil {
+		return 0, err
+	}
+	return w.write(ctx, records)
+}
+
+func (w *Writer) write(ctx context.Context, records []*timestreamwrite.Record) (int64, error) {
+	ingested, err := writeRecords(ctx, w.tsw, w.database, w.table, records)
+	var rejectedErr *RejectedRecordsError
+	if w.DeadLetter != nil && errors.As(err, &rejectedErr) {
+		if dlErr := w.DeadLetter.Put(ctx, rejectedErr.Rejected); dlErr != nil {
+			return ingested, fmt.Errorf("cannot put rejected records into dead letter sink (%v): %w", dlErr, err)
+		}
+	}
+	return ingested, err
+}
+
+// Records converts v into records without writing.
+// v must be a struct, a pointer to struct or a slice of them.
+func (w *Writer) Records(v interface{}) ([]*timestreamwrite.Record, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("cannot write nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		record, err := w.record(rv)
+		if err != nil {
+			return nil, err
+		}
+		return []*timestreamwrite.Record{record}, nil
+	}
+	records := make([]*timestreamwrite.Record, rv.Len())
+	for i := range records {
+		record, err := w.record(rv.Index(i))
+		if err != nil {
+			return nil, fmt.Errorf("#%d: %w", i, err)
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+func (w *Writer) record(rv reflect.Value) (*timestreamwrite.Record, error) {
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("cannot write nil")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot write %s as record", rv.Type())
+	}
+	schema, err := schemaOf(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	return schema.record(rv, w.MultiMeasureName)
+}
+
+type fieldKind int
+
+const (
+	fieldTime fieldKind = iota + 1
+	fieldDimension
+	fieldMeasure
+	fieldMeasureName
+)
+
+type recordField struct {
+	index []int
+	kind  fieldKind
+	name  string
+	// option is a time unit for time and a measure value type for measures
+	option string
+}
+
+// recordSchema is a mapping between a struct type and records.
+type recordSchema struct {
+	typ         reflect.Type
+	time        *recordField
+	measureName *recordField
+	dimensions  []recordField
+	measures    []recordField
+}
+
+type tagOptions struct {
+	kind    string
+	name    string
+	options []string
+}
+
+func parseTag(sf reflect.StructField) (tagOptions, bool) {
+	tag, ok := sf.Tag.Lookup(tagName)
+	if !ok || tag == "-" {
+		return tagOptions{}, false
+	}
+	elems := strings.Split(tag, ",")
+	opts := tagOptions{kind: elems[0], name: sf.Name}
+	for _, elem := range elems[1:] {
+		if strings.HasPrefix(elem, "name=") {
+			opts.name = strings.TrimPrefix(elem, "name=")
+			continue
+		}
+		opts.options = append(opts.options, elem)
+	}
+	return opts, true
+}
+
+func schemaOf(typ reflect.Type) (*recordSchema, error) {
+	if cached, ok := schemaCache.Load(typ); ok {
+		return cached.(*recordSchema), nil
+	}
+	schema := &recordSchema{typ: typ}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		opts, ok := parseTag(sf)
+		if !ok {
+			continue
+		}
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("%s.%s: unexported field cannot be tagged", typ, sf.Name)
+		}
+		field := recordField{index: sf.Index, name: opts.name}
+		option := ""
+		if len(opts.options) > 0 {
+			option = opts.options[0]
+		}
+		switch opts.kind {
+		case "time":
+			if schema.time != nil {
+				return nil, fmt.Errorf("%s: multiple time fields", typ)
+			}
+			if option == "" {
+				option = "ms"
+			}
+			unit, ok := timeUnits[option]
+			if !ok {
+				return nil, fmt.Errorf("%s.%s: unknown time unit: %s", typ, sf.Name, option)
+			}
+			field.kind, field.option = fieldTime, unit
+			schema.time = &field
+		case "dimension":
+			field.kind = fieldDimension
+			schema.dimensions = append(schema.dimensions, field)
+		case "measure":
+			field.kind = fieldMeasure
+			if option == "" {
+				field.option = inferMeasureValueType(sf.Type)
+			} else {
+				field.option = measureValueTypes[option]
+			}
+			if field.option == "" {
+				return nil, fmt.Errorf("%s.%s: unknown measure type: %s", typ, sf.Name, option)
+			}
+			schema.measures = append(schema.measures, field)
+		case "measure_name":
+			field.kind = fieldMeasureName
+			schema.measureName = &field
+		default:
+			return nil, fmt.Errorf("%s.%s: unknown kind: %q", typ, sf.Name, opts.kind)
+		}
+	}
+	if schema.time == nil {
+		return nil, fmt.Errorf("%s: no time field", typ)
+	}
+	if len(schema.measures) == 0 {
+		return nil, fmt.Errorf("%s: no measure fields", typ)
+	}
+	schemaCache.Store(typ, schema)
+	return schema, nil
+}
+
+func inferMeasureValueType(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return timestreamwrite.MeasureValueTypeTimestamp
+	}
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return timestreamwrite.MeasureValueTypeDouble
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return timestreamwrite.MeasureValueTypeBigint
+	case reflect.String:
+		return timestreamwrite.MeasureValueTypeVarchar
+	case reflect.Bool:
+		return timestreamwrite.MeasureValueTypeBoolean
+	default:
+		return ""
+	}
+}
+
+func (s *recordSchema) record(rv reflect.Value, multiMeasureName string) (*timestreamwrite.Record, error) {
+	record := &timestreamwrite.Record{}
+
+	t, ok := indirectField(rv, s.time.index)
+	if !ok {
+		return nil, fmt.Errorf("%s: time is nil", s.typ)
+	}
+	epoch, err := formatEpoch(t, s.time.option)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.typ, err)
+	}
+	record.Time = aws.String(epoch)
+	record.TimeUnit = aws.String(s.time.option)
+
+	for _, field := range s.dimensions {
+		fv, ok := indirectField(rv, field.index)
+		if !ok {
+			continue
+		}
+		value, err := formatField(fv)
+		if err != nil {
+			return nil, fmt.Errorf("%s: dimension (%s): %w", s.typ, field.name, err)
+		}
+		if value == "" {
+			continue
+		}
+		record.Dimensions = append(record.Dimensions, &timestreamwrite.Dimension{
+			Name:               aws.String(field.name),
+			Value:              aws.String(value),
+			DimensionValueType: aws.String(timestreamwrite.DimensionValueTypeVarchar),
+		})
+	}
+
+	measureName := ""
+	if s.measureName != nil {
+		if fv, ok := indirectField(rv, s.measureName.index); ok {
+			if measureName, err = formatField(fv); err != nil {
+				return nil, fmt.Errorf("%s: measure_name: %w", s.typ, err)
+			}
+		}
+	}
+
+	if len(s.measures) == 1 {
+		field := s.measures[0]
+		fv, ok := indirectField(rv, field.index)
+		if !ok {
+			return nil, fmt.Errorf("%s: measure (%s) is nil", s.typ, field.name)
+		}
+		value, err := formatMeasure(fv, field.option)
+		if err != nil {
+			return nil, fmt.Errorf("%s: measure (%s): %w", s.typ, field.name, err)
+		}
+		if measureName == "" {
+			measureName = field.name
+		}
+		record.MeasureName = aws.String(measureName)
+		record.MeasureValue = aws.String(value)
+		record.MeasureValueType = aws.String(field.option)
+		return record, nil
+	}
+
+	if measureName == "" {
+		measureName = multiMeasureName
+	}
+	if measureName == "" {
+		return nil, fmt.Errorf("%s: no measure name given for multi-measure record", s.typ)
+	}
+	record.MeasureName = aws.String(measureName)
+	record.MeasureValueType = aws.String(timestreamwrite.MeasureValueTypeMulti)
+	for _, field := range s.measures {
+		fv, ok := indirectField(rv, field.index)
+		if !ok {
+			continue
+		}
+		value, err := formatMeasure(fv, field.option)
+		if err != nil {
+			return nil, fmt.Errorf("%s: measure (%s): %w", s.typ, field.name, err)
+		}
+		record.MeasureValues = append(record.MeasureValues, &timestreamwrite.MeasureValue{
+			Name:  aws.String(field.name),
+			Value: aws.String(value),
+			Type:  aws.String(field.option),
+		})
+	}
+	if len(record.MeasureValues) == 0 {
+		return nil, fmt.Errorf("%s: all measures are nil", s.typ)
+	}
+	return record, nil
+}
+
+// indirectField returns the field value dereferencing pointers; false is returned if the field is nil.
+func indirectField(rv reflect.Value, index []int) (reflect.Value, bool) {
+	fv := rv.FieldByIndex(index)
+	for fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return reflect.Value{}, false
+		}
+		fv = fv.Elem()
+	}
+	return fv, true
+}
+
+func formatEpoch(fv reflect.Value, unit string) (string, error) {
+	if t, ok := fv.Interface().(time.Time); ok {
+		return strconv.FormatInt(t.UnixNano()/int64(timeUnitDurations[unit]), 10), nil
+	}
+	switch fv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	default:
+		return "", fmt.Errorf("cannot use %s as time", fv.Type())
+	}
+}
+
+func formatMeasure(fv reflect.Value, measureValueType string) (string, error) {
+	if measureValueType == timestreamwrite.MeasureValueTypeTimestamp {
+		return formatEpoch(fv, timestreamwrite.TimeUnitMilliseconds)
+	}
+	return formatField(fv)
+}
+
+func formatField(fv reflect.Value) (string, error) {
+	if t, ok := fv.Interface().(time.Time); ok {
+		return t.UTC().Format(tsTimeLayout), nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, fv.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	default:
+		if s, ok := fv.Interface().(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+		return "", fmt.Errorf("cannot format %s", fv.Type())
+	}
+}
+
+// writeRecords writes records in batches of WriteRecords limit and returns the number of ingested records.
+// Dimensions shared by all records of a batch are sent as CommonAttributes.
+//
+// Rejected records do not stop writing following batches; they are reported as *RejectedRecordsError after all batches are written.
+// If a following batch fails, *RejectedRecordsError still holds the records rejected so far and wraps the failure as Err.
+func writeRecords(ctx context.Context, tsw timestreamwriteiface.TimestreamWriteAPI, database, table string, records []*timestreamwrite.Record) (int64, error) {
+	var (
+		ingested int64
+		rejected []RejectedRecord
+	)
+	for start := 0; start < len(records); start += maxRecordsPerWrite {
+		end := start + maxRecordsPerWrite
+		if end > len(records) {
+			end = len(records)
+		}
+		common, batch := factorCommonDimensions(records[start:end])
+		out, err := tsw.WriteRecordsWithContext(ctx, &timestreamwrite.WriteRecordsInput{
+			DatabaseName:     aws.String(database),
+			TableName:        aws.String(table),
+			CommonAttributes: common,
+			Records:          batch,
+		})
+		var rejectedEx *timestreamwrite.RejectedRecordsException
+		if errors.As(err, &rejectedEx) {
+			rr := rejectedRecords(rejectedEx, records, start)
+			rejected = append(rejected, rr...)
+			ingested += int64(end - start - len(rr))
+			continue
+		}
+		if err != nil {
+			err = &UnwrittenRecordsError{Unwritten: records[start:], Ingested: ingested, Err: err}
+			if len(rejected) > 0 {
+				return ingested, &RejectedRecordsError{Rejected: rejected, Ingested: ingested, Err: err}
+			}
+			return ingested, err
+		}
+		if out.RecordsIngested != nil && out.RecordsIngested.Total != nil {
+			ingested += *out.RecordsIngested.Total
+		} else {
+			ingested += int64(end - start)
+		}
+	}
+	if len(rejected) > 0 {
+		return ingested, &RejectedRecordsError{Rejected: rejected, Ingested: ingested}
+	}
+	return ingested, nil
+}
+
+// factorCommonDimensions moves dimensions that all records share into common attributes.
+// Given records are not modified; records that some dimensions are removed are copied.
+func factorCommonDimensions(records []*timestreamwrite.Record) (*timestreamwrite.Record, []*timestreamwrite.Record) {
+	if len(records) < 2 {
+		return nil, records
+	}
+	type dimensionKey struct{ name, value string }
+	// counts is the number of records that have the dimension; a dimension repeated in a record is counted once
+	counts := map[dimensionKey]int{}
+	for _, record := range records {
+		seen := map[dimensionKey]bool{}
+		for _, dim := range record.Dimensions {
+			key := dimensionKey{aws.StringValue(dim.Name), aws.StringValue(dim.Value)}
+			if !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+	var common []*timestreamwrite.Dimension
+	added := map[dimensionKey]bool{}
+	for _, dim := range records[0].Dimensions {
+		key := dimensionKey{aws.StringValue(dim.Name), aws.StringValue(dim.Value)}
+		if counts[key] == len(records) && !added[key] {
+			added[key] = true
+			common = append(common, dim)
+		}
+	}
+	if len(common) == 0 {
+		return nil, records
+	}
+	batch := make([]*timestreamwrite.Record, len(records))
+	for i, record := range records {
+		copied := *record
+		copied.Dimensions = nil
+		for _, dim := range record.Dimensions {
+			if counts[dimensionKey{aws.StringValue(dim.Name), aws.StringValue(dim.Value)}] != len(records) {
+				copied.Dimensions = append(copied.Dimensions, dim)
+			}
+		}
+		batch[i] = &copied
+	}
+	return &timestreamwrite.Record{Dimensions: common}, batch
+}
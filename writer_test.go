@@ -0,0 +1,232 @@
+package timestreamdriver
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/service/timestreamwrite"
+)
+
+type cpuMetric struct {
+	Time   time.Time `ts:"time,ms"`
+	Host   string    `ts:"dimension,name=host"`
+	Region *string   `ts:"dimension,name=region"`
+	Usage  float64   `ts:"measure,double,name=cpu_usage"`
+	Memo   string
+	Secret string `ts:"-"`
+}
+
+type hostMetric struct {
+	Time    int64   `ts:"time,s"`
+	Host    string  `ts:"dimension,name=host"`
+	Kind    string  `ts:"measure_name"`
+	CPU     float64 `ts:"measure,name=cpu"`
+	Memory  *int64  `ts:"measure,name=memory"`
+	Healthy bool    `ts:"measure,name=healthy"`
+}
+
+func dimension(name, value string) *timestreamwrite.Dimension {
+	return &timestreamwrite.Dimension{Name: aws.String(name), Value: aws.String(value), DimensionValueType: aws.String("VARCHAR")}
+}
+
+func TestWriter_Records(t *testing.T) {
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	memory := int64(1024)
+	cases := []struct {
+		name             string
+		multiMeasureName string
+		arg              interface{}
+		want             []*timestreamwrite.Record
+		wantErr          bool
+	}{
+		{
+			"single measure",
+			"",
+			cpuMetric{Time: ts, Host: "host-1", Region: aws.String("ap-northeast-1"), Usage: 0.5},
+			[]*timestreamwrite.Record{{
+				Time: aws.String("1577934245000"), TimeUnit: aws.String("MILLISECONDS"),
+				Dimensions:  []*timestreamwrite.Dimension{dimension("host", "host-1"), dimension("region", "ap-northeast-1")},
+				MeasureName: aws.String("cpu_usage"), MeasureValue: aws.String("0.5"), MeasureValueType: aws.String("DOUBLE"),
+			}},
+			false,
+		},
+		{
+			"slice of pointers",
+			"",
+			[]*cpuMetric{{Time: ts, Host: "host-1", Usage: 0.5}, {Time: ts, Host: "host-2", Usage: 1}},
+			[]*timestreamwrite.Record{
+				{
+					Time: aws.String("1577934245000"), TimeUnit: aws.String("MILLISECONDS"),
+					Dimensions:  []*timestreamwrite.Dimension{dimension("host", "host-1")},
+					MeasureName: aws.String("cpu_usage"), MeasureValue: aws.String("0.5"), MeasureValueType: aws.String("DOUBLE"),
+				},
+				{
+					Time: aws.String("1577934245000"), TimeUnit: aws.String("MILLISECONDS"),
+					Dimensions:  []*timestreamwrite.Dimension{dimension("host", "host-2")},
+					MeasureName: aws.String("cpu_usage"), MeasureValue: aws.String("1"), MeasureValueType: aws.String("DOUBLE"),
+				},
+			},
+			false,
+		},
+		{
+			"multi measure",
+			"",
+			&hostMetric{Time: 1577934245, Host: "host-1", Kind: "host", CPU: 0.5, Memory: &memory, Healthy: true},
+			[]*timestreamwrite.Record{{
+				Time: aws.String("1577934245"), TimeUnit: aws.String("SECONDS"),
+				Dimensions:       []*timestreamwrite.Dimension{dimension("host", "host-1")},
+				MeasureName:      aws.String("host"),
+				MeasureValueType: aws.String("MULTI"),
+				MeasureValues: []*timestreamwrite.MeasureValue{
+					{Name: aws.String("cpu"), Value: aws.String("0.5"), Type: aws.String("DOUBLE")},
+					{Name: aws.String("memory"), Value: aws.String("1024"), Type: aws.String("BIGINT")},
+					{Name: aws.String("healthy"), Value: aws.String("true"), Type: aws.String("BOOLEAN")},
+				},
+			}},
+			false,
+		},
+		{
+			"multi measure/default name",
+			"metrics",
+			hostMetric{Time: 1577934245, Host: "host-1", CPU: 0.5},
+			[]*timestreamwrite.Record{{
+				Time: aws.String("1577934245"), TimeUnit: aws.String("SECONDS"),
+				Dimensions:       []*timestreamwrite.Dimension{dimension("host", "host-1")},
+				MeasureName:      aws.String("metrics"),
+				MeasureValueType: aws.String("MULTI"),
+				MeasureValues: []*timestreamwrite.MeasureValue{
+					{Name: aws.String("cpu"), Value: aws.String("0.5"), Type: aws.String("DOUBLE")},
+					{Name: aws.String("healthy"), Value: aws.String("false"), Type: aws.String("BOOLEAN")},
+				},
+			}},
+			false,
+		},
+		{"multi measure/no name", "", hostMetric{Time: 1577934245, Host: "host-1", CPU: 0.5}, nil, true},
+		{"not struct", "", 1, nil, true},
+		{"nil", "", (*cpuMetric)(nil), nil, true},
+		{"no time", "", struct {
+			V float64 `ts:"measure"`
+		}{}, nil, true},
+		{"no measures", "", struct {
+			T time.Time `ts:"time"`
+		}{}, nil, true},
+		{"unknown kind", "", struct {
+			T time.Time `ts:"time"`
+			V float64   `ts:"metric"`
+		}{}, nil, true},
+		{"unknown time unit", "", struct {
+			T time.Time `ts:"time,h"`
+			V float64   `ts:"measure"`
+		}{}, nil, true},
+		{"unknown measure type", "", struct {
+			T time.Time `ts:"time"`
+			V float64   `ts:"measure,float"`
+		}{}, nil, true},
+		{"unexported field", "", struct {
+			T    time.Time `ts:"time"`
+			host string    `ts:"dimension"`
+			V    float64   `ts:"measure"`
+		}{host: "host-1"}, nil, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := &Writer{MultiMeasureName: c.multiMeasureName}
+			got, err := w.Records(c.arg)
+			if (err != nil) != c.wantErr {
+				t.Errorf("wantErr=%v err=%v", c.wantErr, err)
+				return
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("mismatch\nexpected: %s\n     got: %s", c.want, got)
+			}
+		})
+	}
+}
+
+func newTestWriter(t *testing.T, srv *httptest.Server) *Writer {
+	t.Helper()
+	w, err := NewWriter(&Config{
+		Region:             "us-east-1",
+		Endpoint:           srv.URL,
+		CredentialProvider: &credentials.StaticProvider{Value: credentials.Value{AccessKeyID: "id", SecretAccessKey: "secret"}},
+	}, "db1", "table1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestWriter_Write(t *testing.T) {
+	ws := &writeServer{}
+	srv := httptest.NewServer(ws)
+	defer srv.Close()
+	w := newTestWriter(t, srv)
+
+	metrics := make([]cpuMetric, 150)
+	for i := range metrics {
+		metrics[i] = cpuMetric{Time: time.Unix(int64(i), 0), Host: "host-1", Usage: float64(i)}
+	}
+	metrics[149].Host = "host-2"
+	n, err := w.Write(context.Background(), metrics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 150 {
+		t.Errorf("expected 150 records ingested but got %d", n)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.inputs) != 2 {
+		t.Fatalf("expected 2 WriteRecords calls but got %d", len(ws.inputs))
+	}
+	first, second := ws.inputs[0], ws.inputs[1]
+	if len(first.Records) != 100 || len(second.Records) != 50 {
+		t.Errorf("unexpected batch size: %d, %d", len(first.Records), len(second.Records))
+	}
+	if expected := (&timestreamwrite.Record{Dimensions: []*timestreamwrite.Dimension{dimension("host", "host-1")}}); !reflect.DeepEqual(first.CommonAttributes, expected) {
+		t.Errorf("CommonAttributes: expected=%s got=%s", expected, first.CommonAttributes)
+	}
+	if len(first.Records[0].Dimensions) != 0 {
+		t.Errorf("common dimensions must be removed from records: %s", first.Records[0])
+	}
+	if second.CommonAttributes != nil {
+		t.Errorf("CommonAttributes: expected=nil got=%s", second.CommonAttributes)
+	}
+}
+
+func Test_factorCommonDimensions_KeepsInput(t *testing.T) {
+	records := []*timestreamwrite.Record{
+		{Dimensions: []*timestreamwrite.Dimension{dimension("host", "host-1"), dimension("az", "a")}},
+		{Dimensions: []*timestreamwrite.Dimension{dimension("host", "host-1"), dimension("az", "c")}},
+	}
+	common, batch := factorCommonDimensions(records)
+	if expected := []*timestreamwrite.Dimension{dimension("host", "host-1")}; !reflect.DeepEqual(common.Dimensions, expected) {
+		t.Errorf("common: expected=%s got=%s", expected, common.Dimensions)
+	}
+	if expected := []*timestreamwrite.Dimension{dimension("az", "c")}; !reflect.DeepEqual(batch[1].Dimensions, expected) {
+		t.Errorf("batch: expected=%s got=%s", expected, batch[1].Dimensions)
+	}
+	if len(records[0].Dimensions) != 2 {
+		t.Errorf("given records must not be modified: %s", records[0])
+	}
+}
+
+func Test_factorCommonDimensions_RepeatedInRecord(t *testing.T) {
+	records := []*timestreamwrite.Record{
+		{Dimensions: []*timestreamwrite.Dimension{dimension("host", "host-1"), dimension("az", "a"), dimension("az", "a")}},
+		{Dimensions: []*timestreamwrite.Dimension{dimension("host", "host-1"), dimension("az", "c")}},
+	}
+	common, batch := factorCommonDimensions(records)
+	if expected := []*timestreamwrite.Dimension{dimension("host", "host-1")}; !reflect.DeepEqual(common.Dimensions, expected) {
+		t.Errorf("common: expected=%s got=%s", expected, common.Dimensions)
+	}
+	if expected := []*timestreamwrite.Dimension{dimension("az", "a"), dimension("az", "a")}; !reflect.DeepEqual(batch[0].Dimensions, expected) {
+		t.Errorf("batch: expected=%s got=%s", expected, batch[0].Dimensions)
+	}
+}